@@ -1,7 +1,12 @@
 package excelformulaparser
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -9,7 +14,9 @@ import (
 type Token struct {
 	Start, End Pos
 	Type       TokenType
-	Raw        string // The raw string value of the token
+	Raw        string   // The raw string value of the token
+	Leading    []*Token // The trivia before the token: whitespace, and the '=' prefix before the first token of a formula
+	Trailing   []*Token // The trivia after the last token of a formula (e.g., a trailing line break), nil for other tokens
 }
 
 func newToken(start, end Pos, t TokenType, raw string) *Token {
@@ -21,6 +28,14 @@ func newToken(start, end Pos, t TokenType, raw string) *Token {
 	}
 }
 
+// space returns the whitespace right before the token, nil if there is none.
+func (t *Token) space() *Token {
+	if n := len(t.Leading); n > 0 && t.Leading[n-1].Type == Whitespace {
+		return t.Leading[n-1]
+	}
+	return nil
+}
+
 type TokenType int
 
 const (
@@ -56,6 +71,13 @@ const (
 	Cell                 // e.g., A1, B2, etc
 	AbsoluteRow          // Absolute row reference (e.g., $1, $2)
 	AbsoluteColumn       // Absolute column reference (e.g., $A, $B)
+	Bracketed            // Text enclosed in brackets (e.g., [Book.xlsx])
+	Spill                // # (spilled range reference operator, e.g., A1#)
+	Intersection         // Whitespace between references (intersection operator, e.g., A1:C5 B2:D8)
+	R1C1Reference        // R1C1 style reference (e.g., R1C1, R[-1]C[2], RC), only in R1C1 mode
+	Backslash            // \ (array column separator in some locales)
+	Period               // . (array column separator in some locales)
+	Whitespace           // Spaces, tabs and line breaks between tokens (trivia)
 )
 
 type Pos struct {
@@ -111,18 +133,360 @@ func newBaseNode(start, end Pos) baseNode {
 	}
 }
 
+// EqualOptions selects the details that EqualNodesWithOptions compares in addition
+// to the structure of the nodes.
+type EqualOptions struct {
+	Positions bool // Compare the positions of nodes and tokens
+	Trivia    bool // Compare whitespace, punctuation (e.g., parentheses and separators) and all tokens as written
+}
+
+// EqualNodes reports whether a and b are the same expression: nodes of the same
+// types with the same names, operators, references and literal values.
+// Positions, whitespace and punctuation are ignored, as is the spelling of
+// localized number literals and function names (e.g., 1,5 and SUMME for 1.5
+// and SUM). Names and references are compared as written.
+func EqualNodes(a, b Node) bool {
+	return EqualNodesWithOptions(a, b, EqualOptions{})
+}
+
+// EqualNodesWithOptions is like EqualNodes, but also compares the details selected by options.
+func EqualNodesWithOptions(a, b Node, options EqualOptions) bool {
+	var x, y bytes.Buffer
+	(&canonicalWriter{w: &x, options: options}).node(a)
+	(&canonicalWriter{w: &y, options: options}).node(b)
+	return bytes.Equal(x.Bytes(), y.Bytes())
+}
+
+// Hash returns a hash of node that is consistent with EqualNodes: equal nodes have
+// the same hash. It is stable across processes and can be stored.
+func Hash(node Node) uint64 {
+	var h = fnv.New64a()
+	(&canonicalWriter{w: h}).node(node)
+	return h.Sum64()
+}
+
+// Clone returns a deep copy of node. All tokens, including trivia, are
+// copied, and identifiers refer to the copied declarations of their LET names
+// and LAMBDA parameters. Declarations outside of node are shared.
+func Clone(node Node) Node {
+	if node == nil {
+		return nil
+	}
+	return (&cloner{tokens: map[*Token]*Token{}}).node(node)
+}
+
+type cloner struct {
+	tokens map[*Token]*Token
+}
+
+func (c *cloner) copy(t *Token) *Token {
+	if t == nil {
+		return nil
+	}
+	var copied = *t
+	copied.Leading = c.trivia(t.Leading)
+	copied.Trailing = c.trivia(t.Trailing)
+	c.tokens[t] = &copied
+	return &copied
+}
+
+func (c *cloner) trivia(list []*Token) []*Token {
+	if list == nil {
+		return nil
+	}
+	var trivia = make([]*Token, len(list))
+	for i, t := range list {
+		trivia[i] = c.copy(t)
+	}
+	return trivia
+}
+
+func (c *cloner) token(t *Token, _ TokenType, _ string) *Token { return c.copy(t) }
+func (c *cloner) alias(t, _ *Token) *Token                     { return c.copy(t) }
+
+func (c *cloner) ref(t *Token) *Token {
+	if copied, ok := c.tokens[t]; ok {
+		return copied
+	}
+	return t
+}
+
+func (c *cloner) node(n Node) Node {
+	n = mapNode(n, c)
+	if s, ok := n.(StructuredRefExpr); ok && s.Items != nil {
+		s.Items = append([]StructuredItem{}, s.Items...)
+		return s
+	}
+	return n
+}
+
+func (c *cloner) span(b baseNode, _, _ Pos) baseNode { return b }
+
+// canonicalWriter writes the parts of a node that EqualNodes compares, so that
+// equal nodes are written as the same bytes.
+type canonicalWriter struct {
+	w       io.Writer
+	options EqualOptions
+}
+
+func (c *canonicalWriter) int(i int) {
+	var buf [binary.MaxVarintLen64]byte
+	c.w.Write(buf[:binary.PutVarint(buf[:], int64(i))])
+}
+
+func (c *canonicalWriter) float(f float64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], math.Float64bits(f))
+	c.w.Write(buf[:])
+}
+
+func (c *canonicalWriter) bool(b bool) {
+	if b {
+		c.int(1)
+	} else {
+		c.int(0)
+	}
+}
+
+func (c *canonicalWriter) string(s string) {
+	c.int(len(s))
+	io.WriteString(c.w, s)
+}
+
+func (c *canonicalWriter) pos(p Pos) {
+	if c.options.Positions {
+		c.int(p.Line)
+		c.int(p.Column)
+	}
+}
+
+// token writes a significant token, e.g. a name: its type and text.
+func (c *canonicalWriter) token(t *Token) {
+	c.bool(t != nil)
+	if t == nil {
+		return
+	}
+	c.int(int(t.Type))
+	c.string(t.Raw)
+	c.pos(t.Start)
+	c.pos(t.End)
+	if c.options.Trivia {
+		c.tokens(t.Leading)
+		c.tokens(t.Trailing)
+	}
+}
+
+// syntax writes a token that is only compared with the Trivia option, e.g. a parenthesis.
+func (c *canonicalWriter) syntax(t *Token) {
+	if c.options.Trivia {
+		c.token(t)
+	}
+}
+
+func (c *canonicalWriter) tokens(list []*Token) {
+	c.int(len(list))
+	for _, t := range list {
+		c.token(t)
+	}
+}
+
+// operator writes an operator token, of which the whitespace of an intersection is trivia.
+func (c *canonicalWriter) operator(t *Token) {
+	if c.options.Trivia || t == nil || t.Type != Intersection {
+		c.token(t)
+		return
+	}
+	c.token(&Token{Type: t.Type, Start: t.Start, End: t.End})
+}
+
+func (c *canonicalWriter) nodes(list []Node) {
+	c.int(len(list))
+	for _, n := range list {
+		c.node(n)
+	}
+}
+
+func (c *canonicalWriter) node(node Node) {
+	if node == nil {
+		c.string("")
+		return
+	}
+	c.string(strings.TrimPrefix(fmt.Sprintf("%T", node), "excelformulaparser."))
+	c.pos(node.Start())
+	c.pos(node.End())
+
+	switch n := node.(type) {
+	case LiteralExpr:
+		if n.Value != nil && n.Value.Type == Number && !c.options.Trivia {
+			c.token(&Token{Type: Number, Start: n.Value.Start, End: n.Value.End})
+		} else {
+			c.token(n.Value)
+		}
+		c.syntax(n.LocalValue)
+		c.float(n.Number)
+		c.int(int(n.ErrorKind))
+
+	case CellExpr:
+		c.token(n.Ident)
+		c.int(n.Row)
+		c.int(n.Col)
+		c.bool(n.RowAbsolute)
+		c.bool(n.ColAbsolute)
+
+	case R1C1Expr:
+		c.syntax(n.Ident)
+		for _, index := range []R1C1Index{n.Row, n.Col} {
+			c.int(index.Value)
+			c.bool(index.Relative)
+			c.bool(index.Omitted)
+		}
+
+	case IdentExpr:
+		c.token(n.Name)
+		c.bool(n.Binding != nil)
+
+	case RangeExpr:
+		c.node(n.Begin)
+		for _, colon := range n.Colons {
+			c.syntax(colon)
+		}
+		c.nodes(n.Ends)
+
+	case SheetRefExpr:
+		c.string(n.Sheet)
+		c.syntax(n.SheetToken)
+		c.syntax(n.Exclamation)
+		c.node(n.Ref)
+
+	case Sheet3DRefExpr:
+		c.string(n.FirstSheet)
+		c.string(n.LastSheet)
+		c.syntax(n.FirstSheetToken)
+		c.syntax(n.Colon)
+		c.syntax(n.LastSheetToken)
+		c.syntax(n.Exclamation)
+		c.node(n.Ref)
+
+	case ExternalRefExpr:
+		c.string(n.Path)
+		c.string(n.Workbook)
+		c.string(n.Sheet)
+		c.string(n.LastSheet)
+		if c.options.Trivia {
+			c.tokens(n.Prefix)
+		}
+		c.syntax(n.Exclamation)
+		c.node(n.Ref)
+
+	case StructuredRefExpr:
+		c.token(n.Table)
+		c.syntax(n.Specifier)
+		c.int(len(n.Items))
+		for _, item := range n.Items {
+			c.int(int(item))
+		}
+		c.string(n.FirstColumn)
+		c.string(n.LastColumn)
+
+	case BinaryExpr:
+		c.node(n.Left)
+		c.operator(n.Operator)
+		c.node(n.Right)
+
+	case UnaryExpr:
+		c.operator(n.Operator)
+		c.node(n.Operand)
+
+	case ParenthesizedExpr:
+		c.syntax(n.ParenOpen)
+		c.node(n.Inner)
+		c.syntax(n.ParenClose)
+
+	case FunCallExpr:
+		c.token(n.Name)
+		c.syntax(n.LocalName)
+		c.syntax(n.ParanOpen)
+		c.nodes(n.Arguments)
+		if c.options.Trivia {
+			c.tokens(n.Separators)
+		}
+		c.syntax(n.ParanClose)
+
+	case CallExpr:
+		c.node(n.Callee)
+		c.syntax(n.ParenOpen)
+		c.nodes(n.Arguments)
+		if c.options.Trivia {
+			c.tokens(n.Separators)
+		}
+		c.syntax(n.ParenClose)
+
+	case LetExpr:
+		c.syntax(n.Name)
+		c.syntax(n.ParenOpen)
+		c.int(len(n.Bindings))
+		for _, binding := range n.Bindings {
+			c.token(binding.Name)
+			c.node(binding.Value)
+		}
+		c.node(n.Body)
+		if c.options.Trivia {
+			c.tokens(n.Separators)
+		}
+		c.syntax(n.ParenClose)
+
+	case LambdaExpr:
+		c.syntax(n.Name)
+		c.syntax(n.ParenOpen)
+		c.tokens(n.Params)
+		c.node(n.Body)
+		if c.options.Trivia {
+			c.tokens(n.Separators)
+		}
+		c.syntax(n.ParenClose)
+
+	case EmptyArgExpr:
+		// nothing to do
+
+	case ArrayExpr:
+		c.syntax(n.BraceOpen)
+		c.int(len(n.Elements))
+		for _, row := range n.Elements {
+			c.nodes(row)
+		}
+		if c.options.Trivia {
+			c.tokens(n.Separators)
+		}
+		c.syntax(n.BraceClose)
+
+	default:
+		panic(fmt.Sprintf("excelformulaparser.EqualNodes: unexpected node type %T", n))
+	}
+}
+
 var _ Node = (*FunCallExpr)(nil)
 var _ Node = (*BinaryExpr)(nil)
 var _ Node = (*UnaryExpr)(nil)
 var _ Node = (*LiteralExpr)(nil)
 var _ Node = (*IdentExpr)(nil)
 var _ Node = (*RangeExpr)(nil)
+var _ Node = (*SheetRefExpr)(nil)
+var _ Node = (*Sheet3DRefExpr)(nil)
+var _ Node = (*ExternalRefExpr)(nil)
+var _ Node = (*StructuredRefExpr)(nil)
+var _ Node = (*R1C1Expr)(nil)
+var _ Node = (*LetExpr)(nil)
+var _ Node = (*LambdaExpr)(nil)
+var _ Node = (*CallExpr)(nil)
+var _ Node = (*EmptyArgExpr)(nil)
 
 type FunCallExpr struct {
 	baseNode
-	Name       *Token // Function name token
+	Name       *Token // Function name token, translated to English when parsed with a localized Locale
+	LocalName  *Token // The localized function name as written (e.g., SUMME), nil if not translated
 	ParanOpen  *Token
-	Arguments  []Node // Arguments can be any expression type
+	Arguments  []Node   // Arguments can be any expression type
+	Separators []*Token // The argument separator tokens
 	ParanClose *Token
 }
 
@@ -141,6 +505,99 @@ func (f FunCallExpr) String() string {
 	return sb.String()
 }
 
+type LetBinding struct {
+	Name  *Token // The declared name
+	Value Node   // The value bound to the name
+}
+
+type LetExpr struct {
+	baseNode
+	Name       *Token       // The LET function name token
+	ParenOpen  *Token       // The opening parenthesis
+	Bindings   []LetBinding // The name and value pairs, in declaration order
+	Body       Node         // The calculation using the names
+	Separators []*Token     // The argument separator tokens, in source order
+	ParenClose *Token       // The closing parenthesis
+}
+
+func (l LetExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString("LetExpr(Bindings: [")
+	for i, binding := range l.Bindings {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(binding.Name.Raw)
+		sb.WriteString(" = ")
+		sb.WriteString(binding.Value.String())
+	}
+	sb.WriteString("], Body: ")
+	sb.WriteString(l.Body.String())
+	sb.WriteString(")")
+	return sb.String()
+}
+
+type LambdaExpr struct {
+	baseNode
+	Name       *Token   // The LAMBDA function name token
+	ParenOpen  *Token   // The opening parenthesis
+	Params     []*Token // The parameter names
+	Body       Node     // The calculation using the parameters
+	Separators []*Token // The argument separator tokens, in source order
+	ParenClose *Token   // The closing parenthesis
+}
+
+func (l LambdaExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString("LambdaExpr(Params: [")
+	for i, param := range l.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(param.Raw)
+	}
+	sb.WriteString("], Body: ")
+	sb.WriteString(l.Body.String())
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// CallExpr is the invocation of an expression result, such as an immediately
+// invoked LAMBDA(a,b,a+b)(1,2) or a LAMBDA bound by LET.
+type CallExpr struct {
+	baseNode
+	Callee     Node     // The invoked expression
+	ParenOpen  *Token   // The opening parenthesis
+	Arguments  []Node   // Arguments can be any expression type
+	Separators []*Token // The argument separator tokens
+	ParenClose *Token   // The closing parenthesis
+}
+
+func (c CallExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString("CallExpr(Callee: ")
+	sb.WriteString(c.Callee.String())
+	sb.WriteString(", Arguments: [")
+	for i, arg := range c.Arguments {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(arg.String())
+	}
+	sb.WriteString("])")
+	return sb.String()
+}
+
+// EmptyArgExpr is an omitted function argument, such as the second argument of IF(A1,,0).
+// It starts and ends at the ',' or ')' following the omitted argument.
+type EmptyArgExpr struct {
+	baseNode
+}
+
+func (e EmptyArgExpr) String() string {
+	return "EmptyArgExpr()"
+}
+
 type BinaryExpr struct {
 	baseNode
 	Left     Node   // Left operand
@@ -154,7 +611,7 @@ func (b BinaryExpr) String() string {
 
 type UnaryExpr struct {
 	baseNode
-	Operator *Token // Operator token (e.g., !, -)
+	Operator *Token // Operator token (e.g., -, %, @, #)
 	Operand  Node   // Operand expression
 }
 
@@ -164,7 +621,10 @@ func (u UnaryExpr) String() string {
 
 type LiteralExpr struct {
 	baseNode
-	Value *Token // Token representing the literal value (e.g., number, string, boolean)
+	Value      *Token    // Token representing the literal value (e.g., number, string, boolean)
+	LocalValue *Token    // The localized boolean as written (e.g., WAHR), nil if not translated
+	Number     float64   // The parsed value of a number literal, 0 for other literals
+	ErrorKind  ErrorKind // The kind of an error literal (e.g., ErrorDiv0), 0 for other literals
 }
 
 func (l LiteralExpr) String() string {
@@ -173,7 +633,8 @@ func (l LiteralExpr) String() string {
 
 type IdentExpr struct {
 	baseNode
-	Name *Token
+	Name    *Token
+	Binding *Token // The LET name or LAMBDA parameter declaration the identifier refers to, nil for defined names
 }
 
 func (i IdentExpr) String() string {
@@ -211,6 +672,130 @@ func (r RangeExpr) String() string {
 	return sb.String()
 }
 
+type SheetRefExpr struct {
+	baseNode
+	Sheet       string // The unquoted sheet name (e.g., My Sheet for 'My Sheet'!A1)
+	SheetToken  *Token // The sheet name token as written in the source
+	Exclamation *Token // The '!' token
+	Ref         Node   // The qualified reference (e.g., CellExpr, RangeExpr, IdentExpr)
+}
+
+func (s SheetRefExpr) String() string {
+	return fmt.Sprintf("SheetRefExpr(Sheet: %s, Ref: %s)", s.Sheet, s.Ref)
+}
+
+type Sheet3DRefExpr struct {
+	baseNode
+	FirstSheet      string // The unquoted name of the first sheet (e.g., Jan for Jan:Dec!B2)
+	LastSheet       string // The unquoted name of the last sheet (e.g., Dec for Jan:Dec!B2)
+	FirstSheetToken *Token // The first sheet token, or the whole quoted prefix (e.g., 'Jan 2024:Dec 2024')
+	Colon           *Token // The ':' token between the sheet names, nil for the quoted form
+	LastSheetToken  *Token // The last sheet token, nil for the quoted form
+	Exclamation     *Token // The '!' token
+	Ref             Node   // The qualified reference (e.g., CellExpr, RangeExpr)
+}
+
+func (s Sheet3DRefExpr) String() string {
+	return fmt.Sprintf("Sheet3DRefExpr(FirstSheet: %s, LastSheet: %s, Ref: %s)", s.FirstSheet, s.LastSheet, s.Ref)
+}
+
+type ExternalRefExpr struct {
+	baseNode
+	Path        string   // The directory of the workbook for full-path forms (e.g., C:\dir\), empty otherwise
+	Workbook    string   // The workbook name (e.g., Book.xlsx) or external link index (e.g., 1)
+	Sheet       string   // The sheet name, empty for workbook-level names (e.g., [1]!Name)
+	LastSheet   string   // The last sheet name of a 3D reference (e.g., Dec for [1]Jan:Dec!A1), empty otherwise
+	Prefix      []*Token // The tokens before '!' (e.g., [1] Sheet1), or the single quoted prefix token
+	Exclamation *Token   // The '!' token
+	Ref         Node     // The qualified reference (e.g., CellExpr, RangeExpr, IdentExpr)
+}
+
+// WorkbookIndex returns the external link index of an OOXML-style reference like [1]Sheet1!A1.
+func (e ExternalRefExpr) WorkbookIndex() (int, bool) {
+	index, err := strconv.Atoi(e.Workbook)
+	if err != nil || e.Path != "" {
+		return 0, false
+	}
+	return index, true
+}
+
+func (e ExternalRefExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString("ExternalRefExpr(Workbook: ")
+	sb.WriteString(e.Path)
+	sb.WriteString(e.Workbook)
+	sb.WriteString(", Sheet: ")
+	sb.WriteString(e.Sheet)
+	if e.LastSheet != "" {
+		sb.WriteString(":")
+		sb.WriteString(e.LastSheet)
+	}
+	sb.WriteString(", Ref: ")
+	sb.WriteString(e.Ref.String())
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// StructuredItem is a special item specifier of a structured reference.
+type StructuredItem int
+
+const (
+	ItemAll     StructuredItem = iota + 1 // #All
+	ItemData                              // #Data
+	ItemHeaders                           // #Headers
+	ItemTotals                            // #Totals
+	ItemThisRow                           // #This Row, or @
+)
+
+func (i StructuredItem) String() string {
+	switch i {
+	case ItemAll:
+		return "#All"
+	case ItemData:
+		return "#Data"
+	case ItemHeaders:
+		return "#Headers"
+	case ItemTotals:
+		return "#Totals"
+	case ItemThisRow:
+		return "#This Row"
+	default:
+		return "StructuredItem(" + strconv.Itoa(int(i)) + ")"
+	}
+}
+
+type StructuredRefExpr struct {
+	baseNode
+	Table       *Token           // The table name token, nil when omitted (e.g., [@Col])
+	Specifier   *Token           // The bracketed specifier (e.g., [[#Headers],[Col A]:[Col C]])
+	Items       []StructuredItem // The item specifiers (e.g., #Headers, @)
+	FirstColumn string           // The unescaped column name, empty when no column is specified
+	LastColumn  string           // The last column of a column range, empty otherwise
+}
+
+func (s StructuredRefExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString("StructuredRefExpr(Table: ")
+	if s.Table != nil {
+		sb.WriteString(s.Table.Raw)
+	}
+	sb.WriteString(", Items: [")
+	for i, item := range s.Items {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(item.String())
+	}
+	sb.WriteString("], Columns: ")
+	sb.WriteString(s.FirstColumn)
+	if s.LastColumn != "" {
+		sb.WriteString(":")
+		sb.WriteString(s.LastColumn)
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
 type CellExpr struct {
 	baseNode
 	Ident       *Token // The cell identifier (e.g., A1, B2)
@@ -224,10 +809,53 @@ func (c CellExpr) String() string {
 	return fmt.Sprintf("CellExpr(%s)", c.Ident.Raw)
 }
 
+// R1C1Index is the row or column part of an R1C1 style reference.
+type R1C1Index struct {
+	Value    int  // Zero-based index if absolute (e.g., 0 for R1), offset from the formula cell if relative (e.g., -1 for R[-1])
+	Relative bool // true for relative parts (e.g., R[-1], C[2], R, C)
+	Omitted  bool // true if the part is missing (e.g., the column of R2, a full row reference)
+}
+
+func (i R1C1Index) String() string {
+	switch {
+	case i.Omitted:
+		return ""
+	case i.Relative && i.Value == 0:
+		return ""
+	case i.Relative:
+		return "[" + strconv.Itoa(i.Value) + "]"
+	default:
+		return strconv.Itoa(i.Value + 1)
+	}
+}
+
+type R1C1Expr struct {
+	baseNode
+	Ident *Token    // The reference token (e.g., R1C1, R[-1]C[2])
+	Row   R1C1Index // The row part
+	Col   R1C1Index // The column part
+}
+
+func (r R1C1Expr) String() string {
+	var sb strings.Builder
+	sb.WriteString("R1C1Expr(")
+	if !r.Row.Omitted {
+		sb.WriteString("R")
+		sb.WriteString(r.Row.String())
+	}
+	if !r.Col.Omitted {
+		sb.WriteString("C")
+		sb.WriteString(r.Col.String())
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
 type ArrayExpr struct {
 	baseNode
 	BraceOpen  *Token   // The opening brace token {
 	Elements   [][]Node // [row][column] of expressions
+	Separators []*Token // The row and column separator tokens, in source order
 	BraceClose *Token   // The closing brace token }
 }
 
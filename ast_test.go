@@ -26,3 +26,115 @@ func TestIdent(t *testing.T) {
 		t.Errorf("Expected 'IdentExpr(Name: NAME)', got '%s'", ident.String())
 	}
 }
+
+func TestEqualNodes(t *testing.T) {
+	tests := []struct {
+		a, b      string
+		equal     bool // with the default options
+		positions bool // with EqualOptions.Positions
+		trivia    bool // with EqualOptions.Trivia
+	}{
+		{"=A1+B1", "=A1+B1", true, true, true},
+		{"=A1+B1", "= A1 + B1", true, false, false},
+		{"=A1+B1", "=A1-B1", false, false, false},
+		{"=A1+B1", "=B1+A1", false, false, false},
+		{"=A1+B1", "=(A1+B1)", false, false, false},
+		{"=SUM(A1,B1)", "=SUM(A1, B1)", true, false, false},
+		{"=SUM(A1,B1)", "=SUM(A1,B1,)", false, false, false},
+		{"=SUM(A1,B1)", "=sum(A1,B1)", false, false, false},
+		{"=1.50", "=1.5", true, false, false},
+		{"=-1", "=- 1", false, false, false},
+		{"={1,2;3,4}", "={1,2,3,4}", false, false, false},
+		{"='Sheet1'!A1", "=Sheet1!A1", true, false, false},
+		{"=A1:B2 C1", "=A1:B2  C1", true, false, false},
+		{"=LET(x,1,x)", "=LET(x, 1, x)", true, false, false},
+		{"=LET(x,1,x)", "=LET(y,1,y)", false, false, false},
+		{"=A1\n", "=A1", true, true, false},
+	}
+	for _, test := range tests {
+		a, err := NewParser(test.a).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.a, err)
+			continue
+		}
+		b, err := NewParser(test.b).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.b, err)
+			continue
+		}
+		if got := EqualNodes(a, b); got != test.equal {
+			t.Errorf("For '%s' and '%s', expected %v, got %v", test.a, test.b, test.equal, got)
+		}
+		if got := EqualNodesWithOptions(a, b, EqualOptions{Positions: true}); got != test.positions {
+			t.Errorf("For '%s' and '%s' with positions, expected %v, got %v", test.a, test.b, test.positions, got)
+		}
+		if got := EqualNodesWithOptions(a, b, EqualOptions{Trivia: true}); got != test.trivia {
+			t.Errorf("For '%s' and '%s' with trivia, expected %v, got %v", test.a, test.b, test.trivia, got)
+		}
+		if got := Hash(a) == Hash(b); got != test.equal {
+			t.Errorf("For '%s' and '%s', expected equal hashes %v, got %v", test.a, test.b, test.equal, got)
+		}
+	}
+
+	de, err := NewParserWithOptions("=SUMME(A1;1,5)", ParseOptions{Locale: LocaleDE}).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	en, err := NewParser("=SUM(A1,1.5)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if !EqualNodes(de, en) || Hash(de) != Hash(en) {
+		t.Errorf("Expected the localized formula to equal the English one")
+	}
+	if !EqualNodes(nil, nil) || EqualNodes(en, nil) {
+		t.Errorf("Expected only nil to equal nil")
+	}
+}
+
+func TestHash(t *testing.T) {
+	node, err := NewParser("=SUM(A1:B2, 1)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	// the hash must not change between versions, as it may be stored
+	var expected uint64 = 10577411995100671183
+	if got := Hash(node); got != expected {
+		t.Errorf("Expected %d, got %d", expected, got)
+	}
+}
+
+func TestClone(t *testing.T) {
+	node, err := NewParser(" =LET(x, Table1[@Col], SUM( x, -1 )) \n").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var clone = Clone(node).(LetExpr)
+	if !EqualNodesWithOptions(clone, node, EqualOptions{Positions: true, Trivia: true}) {
+		t.Errorf("Expected the clone to equal the original")
+	}
+	if got := Source(clone); got != Source(node) {
+		t.Errorf("Expected '%s', got '%s'", Source(node), got)
+	}
+	var original = node.(LetExpr)
+	if clone.Name == original.Name || clone.Name.Leading[0] == original.Name.Leading[0] {
+		t.Errorf("Expected the tokens and trivia to be copied")
+	}
+	var x = clone.Body.(FunCallExpr).Arguments[0].(IdentExpr)
+	if x.Binding != clone.Bindings[0].Name {
+		t.Errorf("Expected the identifier to refer to the copied declaration")
+	}
+
+	// changing the clone leaves the original unchanged
+	clone.Bindings[0].Value.(StructuredRefExpr).Items[0] = ItemAll
+	clone.Body.(FunCallExpr).Arguments[1].(LiteralExpr).Value.Raw = "-2"
+	if got := Source(node); got != " =LET(x, Table1[@Col], SUM( x, -1 )) \n" {
+		t.Errorf("Expected the original to be unchanged, got '%s'", got)
+	}
+	if item := original.Bindings[0].Value.(StructuredRefExpr).Items[0]; item != ItemThisRow {
+		t.Errorf("Expected the original item %s, got %s", ItemThisRow, item)
+	}
+	if Clone(nil) != nil {
+		t.Errorf("Expected nil")
+	}
+}
@@ -0,0 +1,149 @@
+package excelformulaparser
+
+// register adds a built-in function that takes minArgs to maxArgs arguments,
+// or any number of arguments from minArgs if maxArgs is -1. Calls with another
+// number of arguments, which Excel rejects when the formula is entered, fail
+// with an EvalError.
+func register(name string, minArgs, maxArgs int, f builtin) {
+	builtins[name] = func(c *funcCall) (Value, error) {
+		switch n := len(c.args); {
+		case n < minArgs:
+			return Value{}, newEvalError(c.node.Start(), "%s requires at least %d arguments, got %d", name, minArgs, n)
+		case maxArgs >= 0 && n > maxArgs:
+			return Value{}, newEvalError(c.node.Start(), "%s accepts at most %d arguments, got %d", name, maxArgs, n)
+		}
+		return f(c)
+	}
+}
+
+// eval evaluates the i-th argument, which may be a reference. An omitted
+// argument is blank.
+func (c *funcCall) eval(i int) (Value, error) {
+	if i >= len(c.args) {
+		return Value{}, nil
+	}
+	return c.e.eval(c.args[i], c.scope)
+}
+
+// value evaluates the i-th argument, and replaces a reference by the cell values.
+func (c *funcCall) value(i int) (Value, error) {
+	v, err := c.eval(i)
+	if err != nil {
+		return Value{}, err
+	}
+	return c.e.deref(v)
+}
+
+// elementwise applies f to a value, or to each element of an array.
+func elementwise(v Value, f func(v Value) Value) Value {
+	if v.Kind != ValueArray {
+		return f(v)
+	}
+	var rows = make([][]Value, len(v.Array))
+	for i, row := range v.Array {
+		rows[i] = make([]Value, len(row))
+		for j, element := range row {
+			rows[i][j] = f(element)
+		}
+	}
+	return NewArray(rows)
+}
+
+// scalar returns a built-in function of single values, applied element-wise
+// to arrays (e.g., LEN({"a","bc"}) is {1,2}). An omitted optional argument
+// is not in args.
+func scalar(f func(args []Value) Value) builtin {
+	return func(c *funcCall) (Value, error) {
+		var args = make([]Value, len(c.args))
+		for i := range c.args {
+			v, err := c.value(i)
+			if err != nil {
+				return Value{}, err
+			}
+			args[i] = v
+		}
+		return lift(args, f), nil
+	}
+}
+
+// numeric returns a built-in function of numbers, e.g. ROUND. The arguments
+// are converted like the operands of arithmetic operators, and f is applied
+// element-wise to arrays (e.g., ROUND({1.5,2.5},0) is {2,3}). An omitted
+// optional argument is not in x.
+func numeric(f func(x []float64) Value) builtin {
+	return scalar(func(args []Value) Value {
+		var x = make([]float64, len(args))
+		for i, arg := range args {
+			n, kind := toNumber(arg)
+			if kind != 0 {
+				return NewError(kind)
+			}
+			x[i] = n
+		}
+		return f(x)
+	})
+}
+
+// flatten returns the values of the i-th argument: the cells of a reference
+// row by row, the elements of an array, or a single value given directly.
+func (c *funcCall) flatten(i int) (values []Value, direct bool, err error) {
+	v, err := c.eval(i)
+	if err != nil {
+		return nil, false, err
+	}
+	switch v.Kind {
+	case ValueReference:
+		for _, area := range v.Areas { // e.g., SUM((A1:A3,C1))
+			cells, err := c.e.deref(NewReference(area))
+			if err != nil {
+				return nil, false, err
+			}
+			values = appendElements(values, cells)
+		}
+		return values, false, nil
+	case ValueArray:
+		return appendElements(nil, v), false, nil
+	default:
+		return []Value{v}, true, nil
+	}
+}
+
+// appendElements appends the elements of an array, or a single value.
+func appendElements(values []Value, v Value) []Value {
+	if v.Kind != ValueArray {
+		return append(values, v)
+	}
+	for _, row := range v.Array {
+		values = append(values, row...)
+	}
+	return values
+}
+
+// numbers returns the numbers of the arguments of a function like SUM, or the
+// first error among them. Like Excel, it converts arguments given directly
+// (e.g., SUM("1",TRUE) is 2), but ignores text, booleans and blank cells in
+// references and arrays.
+func (c *funcCall) numbers() ([]float64, ErrorKind, error) {
+	var numbers []float64
+	for i := range c.args {
+		values, direct, err := c.flatten(i)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, v := range values {
+			switch {
+			case direct:
+				x, kind := toNumber(v)
+				if kind != 0 {
+					return nil, kind, nil
+				}
+				numbers = append(numbers, x)
+			case v.Kind == ValueNumber:
+				numbers = append(numbers, v.Number)
+			case v.Kind == ValueError:
+				return nil, v.Error, nil
+			}
+		}
+	}
+	return numbers, 0, nil
+}
@@ -0,0 +1,344 @@
+package excelformulaparser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Excel converts the operands of operators as follows:
+//
+//   - Arithmetic (+ - * / ^, the unary - and %) converts TRUE to 1, FALSE and
+//     blank to 0, and text that looks like a number, percentage, currency,
+//     date or time to that number (e.g., "1,000", "50%", "$3", "2024-01-31").
+//     Other text, including "", is #VALUE!.
+//   - Concatenation (&) converts numbers to text with up to 15 significant
+//     digits, booleans to TRUE or FALSE, and blank to "".
+//   - Comparisons do not convert: numbers are less than text, which is less
+//     than booleans. Text is compared case-insensitively, numbers to 15
+//     significant digits, and blank is 0, "" or FALSE, like the other operand.
+//   - Conditions (e.g., of IF) are FALSE for 0 and blank, TRUE for other
+//     numbers, and text other than "TRUE" or "FALSE" is #VALUE!.
+//
+// Errors take precedence over any conversion.
+
+// toNumber converts a value to a number for arithmetic, or returns the error of the conversion.
+func toNumber(v Value) (float64, ErrorKind) {
+	switch v.Kind {
+	case ValueBlank:
+		return 0, 0
+	case ValueNumber:
+		return v.Number, 0
+	case ValueBool:
+		if v.Bool {
+			return 1, 0
+		}
+		return 0, 0
+	case ValueString:
+		if x, ok := textToNumber(v.Text); ok {
+			return x, 0
+		}
+		return 0, ErrorValue
+	case ValueError:
+		return 0, v.Error
+	default:
+		return 0, ErrorValue
+	}
+}
+
+// toBool converts a value to a condition, e.g. for IF: numbers other than 0
+// are TRUE, and text must be "TRUE" or "FALSE".
+func toBool(v Value) (bool, ErrorKind) {
+	switch v.Kind {
+	case ValueBlank:
+		return false, 0
+	case ValueBool:
+		return v.Bool, 0
+	case ValueNumber:
+		return v.Number != 0, 0
+	case ValueString:
+		switch {
+		case strings.EqualFold(v.Text, "TRUE"):
+			return true, 0
+		case strings.EqualFold(v.Text, "FALSE"):
+			return false, 0
+		}
+		return false, ErrorValue
+	case ValueError:
+		return false, v.Error
+	default:
+		return false, ErrorValue
+	}
+}
+
+// toText converts a value to a text for concatenation.
+func toText(v Value) string {
+	switch v.Kind {
+	case ValueString:
+		return v.Text
+	case ValueBlank:
+		return ""
+	case ValueNumber:
+		return formatNumber(v.Number)
+	default:
+		return v.String()
+	}
+}
+
+// A converter converts the arguments of a function, and keeps the first error
+// for the function to return.
+type converter struct {
+	err ErrorKind
+}
+
+// number converts a value like toNumber.
+func (c *converter) number(v Value) float64 {
+	x, kind := toNumber(v)
+	if c.err == 0 {
+		c.err = kind
+	}
+	return x
+}
+
+// text converts a value like toText, or sets the error of an error value.
+func (c *converter) text(v Value) string {
+	switch v.Kind {
+	case ValueError:
+		if c.err == 0 {
+			c.err = v.Error
+		}
+		return ""
+	case ValueLambda:
+		if c.err == 0 {
+			c.err = ErrorValue
+		}
+		return ""
+	}
+	return toText(v)
+}
+
+// bool converts a value like toBool.
+func (c *converter) bool(v Value) bool {
+	b, kind := toBool(v)
+	if c.err == 0 {
+		c.err = kind
+	}
+	return b
+}
+
+// formatNumber formats a number like the General number format: rounded to
+// 15 significant digits, in scientific notation (e.g., 1E+15) if it has more
+// integer digits than that or is very small.
+func formatNumber(x float64) string {
+	x = round15(x)
+	if x == 0 {
+		return "0"
+	}
+	if abs := max(x, -x); abs >= 1e15 || abs < 1e-9 {
+		return strconv.FormatFloat(x, 'E', -1, 64)
+	}
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+// round15 rounds a number to 15 significant digits, the precision of Excel.
+func round15(x float64) float64 {
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(x, 'e', 14, 64), 64)
+	return r
+}
+
+var (
+	numberText = regexp.MustCompile(`^(\d{1,3}(,\d{3})+|\d*)(\.\d*)?([eE][+-]?\d+)?$`)
+	isoDate    = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	usDate     = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})/(\d{2}|\d{4})$`)
+	timeText   = regexp.MustCompile(`^(\d{1,2}):(\d{1,2})(:(\d{1,2}(\.\d+)?))?(\s*([AaPp])[Mm])?$`)
+)
+
+// textToNumber converts a text to a number like Excel does for arithmetic,
+// e.g. " 1,000.5", "-$12", "(3)", "50%", "1E3", "2024-01-31" or "9:30 PM".
+// Dates and times become serial numbers.
+func textToNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if x, ok := numberToNumber(s); ok {
+		return x, true
+	}
+	if x, ok := timeToNumber(s); ok {
+		return x, true
+	}
+	if x, ok := dateToNumber(s); ok {
+		return x, true
+	}
+	if i := strings.IndexByte(s, ' '); i > 0 { // a date and a time, e.g. 1/31/2024 9:30 PM
+		date, ok := dateToNumber(s[:i])
+		clock, ok2 := timeToNumber(strings.TrimSpace(s[i+1:]))
+		if ok && ok2 {
+			return date + clock, true
+		}
+	}
+	return 0, false
+}
+
+// numberToNumber converts a number, percentage or currency text.
+func numberToNumber(s string) (float64, bool) {
+	var negative, percent bool
+	if len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')' { // accounting format
+		s, negative = s[1:len(s)-1], true
+	}
+	if strings.HasSuffix(s, "%") {
+		s, percent = strings.TrimSpace(s[:len(s)-1]), true
+	}
+	s = strings.TrimPrefix(s, "$")
+	switch {
+	case strings.HasPrefix(s, "-"):
+		s, negative = s[1:], !negative
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	s = strings.TrimPrefix(s, "$") // e.g., -$1
+	if m := numberText.FindStringSubmatch(s); m == nil || m[1] == "" && len(m[3]) <= 1 {
+		return 0, false // e.g., "abc", "." or "E3"
+	}
+	x, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	if percent {
+		x /= 100
+	}
+	if negative {
+		x = -x
+	}
+	return x, true
+}
+
+// dateToNumber converts a date in ISO (2024-01-31) or US notation (1/31/2024)
+// to a serial number.
+func dateToNumber(s string) (float64, bool) {
+	var year, month, day int
+	if m := isoDate.FindStringSubmatch(s); m != nil {
+		year, _ = strconv.Atoi(m[1])
+		month, _ = strconv.Atoi(m[2])
+		day, _ = strconv.Atoi(m[3])
+	} else if m := usDate.FindStringSubmatch(s); m != nil {
+		month, _ = strconv.Atoi(m[1])
+		day, _ = strconv.Atoi(m[2])
+		year, _ = strconv.Atoi(m[3])
+		if len(m[3]) == 2 { // 00-29 are 2000-2029, 30-99 are 1930-1999
+			year += 1900
+			if year < 1930 {
+				year += 100
+			}
+		}
+	} else {
+		return 0, false
+	}
+	return dateSerial(year, month, day)
+}
+
+// dateSerial returns the serial number of a date in the 1900 date system,
+// where 1 is 1900-01-01. Like Excel, it counts the nonexistent 1900-02-29.
+func dateSerial(year, month, day int) (float64, bool) {
+	if year < 1900 || year > 9999 || month < 1 || month > 12 || day < 1 {
+		return 0, false
+	}
+	if year == 1900 && month == 2 && day == 29 {
+		return 60, true
+	}
+	var date = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return 0, false // e.g., 2023-02-30
+	}
+	var serial = date.Sub(time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC)).Hours() / 24
+	if serial >= 60 {
+		serial++ // after the 1900-02-29 of Excel
+	}
+	return serial, true
+}
+
+// timeToNumber converts a time of day (e.g., 9:30, 21:30:15 or 9:30 PM) to a
+// fraction of a day.
+func timeToNumber(s string) (float64, bool) {
+	var m = timeText.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	var second float64
+	if m[4] != "" {
+		second, _ = strconv.ParseFloat(m[4], 64)
+	}
+	if m[7] != "" { // AM or PM
+		if hour < 1 || hour > 12 {
+			return 0, false
+		}
+		hour %= 12
+		if m[7] == "P" || m[7] == "p" {
+			hour += 12
+		}
+	}
+	if minute > 59 || second >= 60 {
+		return 0, false
+	}
+	return (float64(hour*3600+minute*60) + second) / 86400, true
+}
+
+// compare compares two values for the comparison operators. A blank value is
+// compared as the zero value of the other kind.
+func compare(a, b Value) int {
+	if a.Kind == ValueBlank {
+		a = Value{Kind: b.Kind}
+	}
+	if b.Kind == ValueBlank {
+		b = Value{Kind: a.Kind}
+	}
+	if a.Kind != b.Kind {
+		return compareInts(kindOrder(a.Kind), kindOrder(b.Kind))
+	}
+	switch a.Kind {
+	case ValueNumber:
+		var x, y = round15(a.Number), round15(b.Number)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	case ValueString:
+		return strings.Compare(strings.ToLower(a.Text), strings.ToLower(b.Text))
+	case ValueBool:
+		return compareInts(boolInt(a.Bool), boolInt(b.Bool))
+	default:
+		return 0
+	}
+}
+
+// kindOrder returns the order of the kinds of compared values: numbers < text < booleans.
+func kindOrder(kind ValueKind) int {
+	switch kind {
+	case ValueNumber:
+		return 0
+	case ValueString:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
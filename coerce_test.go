@@ -0,0 +1,110 @@
+package excelformulaparser
+
+import "testing"
+
+func TestCoercion(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		// arithmetic
+		{`="1"+1`, "2"},
+		{"=TRUE+1", "2"},
+		{"=FALSE*5", "0"},
+		{"=C1+1", "1"},
+		{"=-C1", "0"},
+		{`=""+1`, "#VALUE!"},
+		{`="a"+1`, "#VALUE!"},
+		{`=" 2 "*3`, "6"},
+		{`="1,000"*1`, "1000"},
+		{`="1,00"*1`, "#VALUE!"},
+		{`="50%"*1`, "0.5"},
+		{`="$3"+0`, "3"},
+		{`="-$3"+0`, "-3"},
+		{`="(2)"+0`, "-2"},
+		{`="1E3"+0`, "1000"},
+		{`=".5"+0`, "0.5"},
+		{`="."+0`, "#VALUE!"},
+		{`="E3"+0`, "#VALUE!"},
+		{`="2024-01-31"+0`, "45322"},
+		{`="1/31/2024"+0`, "45322"},
+		{`="1900-02-29"+0`, "60"},
+		{`="1900-03-01"+0`, "61"},
+		{`="2023-02-30"+0`, "#VALUE!"},
+		{`="12:00"*1`, "0.5"},
+		{`="6:00 PM"*1`, "0.75"},
+		{`="13:00 PM"*1`, "#VALUE!"},
+		{`="2024-01-31 12:00"+0`, "45322.5"},
+		{`="TRUE"+0`, "#VALUE!"},
+		{`=-"1"`, "-1"},
+		{`="50"%`, "0.5"},
+		{`=2^"2"`, "4"},
+		// concatenation
+		{"=1&TRUE", `"1TRUE"`},
+		{"=C1&1", `"1"`},
+		{`=""&0.1+0.2`, `"0.3"`},
+		{`=""&1/3`, `"0.333333333333333"`},
+		{`=""&10^15`, `"1E+15"`},
+		{`=""&2^60`, `"1.15292150460685E+18"`},
+		{`=""&1E-10`, `"1E-10"`},
+		{`=""&-0.000001`, `"-0.000001"`},
+		// comparison
+		{`="a"<"B"`, "TRUE"},
+		{`="abc"="ABC"`, "TRUE"},
+		{`="a"<>"A"`, "FALSE"},
+		{"=0.1+0.2=0.3", "TRUE"},
+		{`=1="1"`, "FALSE"},
+		{`=1<"a"`, "TRUE"},
+		{`=1E+100<""`, "TRUE"},
+		{`="a"<FALSE`, "TRUE"},
+		{"=1<FALSE", "TRUE"},
+		{"=FALSE<TRUE", "TRUE"},
+		{"=C1=FALSE", "TRUE"},
+		{`=C1=""`, "TRUE"},
+		{"=C1=0", "TRUE"},
+		{"=C1<1", "TRUE"},
+		{`=C1<"a"`, "TRUE"},
+		{"=C1=C2", "TRUE"},
+		{"=B2>A1", "TRUE"},
+		// errors take precedence
+		{`=#N/A="a"`, "#N/A"},
+		{"=1&#DIV/0!", "#DIV/0!"},
+		{`="a"+#REF!`, "#VALUE!"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		v, err := Evaluate(node, &EvalContext{Resolver: testCells})
+		if err != nil {
+			t.Errorf("For input '%s', unexpected error: %v", test.src, err)
+			continue
+		}
+		if got := v.String(); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+	}
+}
+
+func Test_formatNumber(t *testing.T) {
+	tests := []struct {
+		number   float64
+		expected string
+	}{
+		{0, "0"},
+		{-1.5, "-1.5"},
+		{0.1 + 0.2, "0.3"},
+		{123456789012345, "123456789012345"},
+		{1234567890123456, "1.23456789012346E+15"},
+		{1e100, "1E+100"},
+		{0.000000001, "0.000000001"},
+		{1.5e-12, "1.5E-12"},
+	}
+	for _, test := range tests {
+		if got := formatNumber(test.number); got != test.expected {
+			t.Errorf("For %v, expected '%s', got '%s'", test.number, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package excelformulaparser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrorKind identifies an Excel error value such as #DIV/0! or #N/A.
+type ErrorKind int
+
+const (
+	ErrorNull        ErrorKind = iota + 1 // #NULL!
+	ErrorDiv0                             // #DIV/0!
+	ErrorValue                            // #VALUE!
+	ErrorRef                              // #REF!
+	ErrorName                             // #NAME?
+	ErrorNum                              // #NUM!
+	ErrorNA                               // #N/A
+	ErrorGettingData                      // #GETTING_DATA
+	ErrorSpill                            // #SPILL!
+	ErrorConnect                          // #CONNECT!
+	ErrorBlocked                          // #BLOCKED!
+	ErrorUnknown                          // #UNKNOWN!
+	ErrorField                            // #FIELD!
+	ErrorCalc                             // #CALC!
+	ErrorBusy                             // #BUSY!
+	ErrorPython                           // #PYTHON!
+)
+
+// ErrorInfo describes a registered error value.
+type ErrorInfo struct {
+	Kind    ErrorKind
+	Literal string // The spelling in formulas (e.g., #DIV/0!)
+	Code    int    // The number returned by ERROR.TYPE
+}
+
+type errorRegistry struct {
+	mu     sync.RWMutex
+	values []ErrorInfo // indexed by ErrorKind - 1
+}
+
+var errorValues = &errorRegistry{
+	values: []ErrorInfo{
+		{ErrorNull, "#NULL!", 1},
+		{ErrorDiv0, "#DIV/0!", 2},
+		{ErrorValue, "#VALUE!", 3},
+		{ErrorRef, "#REF!", 4},
+		{ErrorName, "#NAME?", 5},
+		{ErrorNum, "#NUM!", 6},
+		{ErrorNA, "#N/A", 7},
+		{ErrorGettingData, "#GETTING_DATA", 8},
+		{ErrorSpill, "#SPILL!", 9},
+		{ErrorConnect, "#CONNECT!", 10},
+		{ErrorBlocked, "#BLOCKED!", 11},
+		{ErrorUnknown, "#UNKNOWN!", 12},
+		{ErrorField, "#FIELD!", 13},
+		{ErrorCalc, "#CALC!", 14},
+		{ErrorBusy, "#BUSY!", 20},
+		{ErrorPython, "#PYTHON!", 19},
+	},
+}
+
+// RegisterErrorValue adds an error value to the set recognized by the lexer,
+// e.g. an error introduced by a newer Excel version. The literal must start
+// with '#' and must not be registered yet.
+func RegisterErrorValue(literal string, code int) (ErrorKind, error) {
+	if !strings.HasPrefix(literal, "#") || len(literal) < 2 {
+		return 0, fmt.Errorf("invalid error value %q: must start with '#'", literal)
+	}
+	errorValues.mu.Lock()
+	defer errorValues.mu.Unlock()
+	for _, info := range errorValues.values {
+		if strings.EqualFold(info.Literal, literal) {
+			return 0, fmt.Errorf("error value %s is already registered", info.Literal)
+		}
+	}
+	var kind = ErrorKind(len(errorValues.values) + 1)
+	errorValues.values = append(errorValues.values, ErrorInfo{Kind: kind, Literal: literal, Code: code})
+	return kind, nil
+}
+
+// LookupErrorValue returns the error value spelled as literal (case-insensitive).
+func LookupErrorValue(literal string) (ErrorInfo, bool) {
+	errorValues.mu.RLock()
+	defer errorValues.mu.RUnlock()
+	for _, info := range errorValues.values {
+		if strings.EqualFold(info.Literal, literal) {
+			return info, true
+		}
+	}
+	return ErrorInfo{}, false
+}
+
+// ErrorValues returns all registered error values ordered by kind.
+func ErrorValues() []ErrorInfo {
+	errorValues.mu.RLock()
+	defer errorValues.mu.RUnlock()
+	return append([]ErrorInfo(nil), errorValues.values...)
+}
+
+// Info returns the registered description of the error kind.
+func (k ErrorKind) Info() (ErrorInfo, bool) {
+	errorValues.mu.RLock()
+	defer errorValues.mu.RUnlock()
+	if k < 1 || int(k) > len(errorValues.values) {
+		return ErrorInfo{}, false
+	}
+	return errorValues.values[k-1], true
+}
+
+// Code returns the number ERROR.TYPE reports for the error kind, 0 if unknown.
+func (k ErrorKind) Code() int {
+	info, _ := k.Info()
+	return info.Code
+}
+
+// String returns the literal spelling of the error kind (e.g., #DIV/0!).
+func (k ErrorKind) String() string {
+	if info, ok := k.Info(); ok {
+		return info.Literal
+	}
+	return "ErrorKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// MarshalText returns the literal spelling of the error kind, so that error
+// kinds encode as e.g. "#N/A" in JSON.
+func (k ErrorKind) MarshalText() ([]byte, error) {
+	info, ok := k.Info()
+	if !ok {
+		return nil, fmt.Errorf("invalid error kind %d", int(k))
+	}
+	return []byte(info.Literal), nil
+}
+
+// UnmarshalText sets the error kind spelled as text (case-insensitive).
+func (k *ErrorKind) UnmarshalText(text []byte) error {
+	info, ok := LookupErrorValue(string(text))
+	if !ok {
+		return fmt.Errorf("unknown error value %q", text)
+	}
+	*k = info.Kind
+	return nil
+}
+
+// matchErrorValue returns the longest registered error value at the start of src.
+func matchErrorValue(src []rune) (ErrorInfo, int, bool) {
+	errorValues.mu.RLock()
+	defer errorValues.mu.RUnlock()
+	var match ErrorInfo
+	var matchLen = 0
+	for _, info := range errorValues.values {
+		var literal = []rune(info.Literal)
+		if len(literal) <= matchLen || len(literal) > len(src) {
+			continue
+		}
+		if strings.EqualFold(string(src[:len(literal)]), info.Literal) {
+			match, matchLen = info, len(literal)
+		}
+	}
+	return match, matchLen, matchLen > 0
+}
@@ -0,0 +1,81 @@
+package excelformulaparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		kind    ErrorKind
+		literal string
+		code    int
+	}{
+		{ErrorNull, "#NULL!", 1},
+		{ErrorDiv0, "#DIV/0!", 2},
+		{ErrorValue, "#VALUE!", 3},
+		{ErrorRef, "#REF!", 4},
+		{ErrorName, "#NAME?", 5},
+		{ErrorNum, "#NUM!", 6},
+		{ErrorNA, "#N/A", 7},
+		{ErrorGettingData, "#GETTING_DATA", 8},
+		{ErrorSpill, "#SPILL!", 9},
+		{ErrorCalc, "#CALC!", 14},
+	}
+	for _, test := range tests {
+		if test.kind.String() != test.literal {
+			t.Errorf("Expected %s, got %s", test.literal, test.kind.String())
+		}
+		if test.kind.Code() != test.code {
+			t.Errorf("Expected code %d for %s, got %d", test.code, test.literal, test.kind.Code())
+		}
+		info, ok := LookupErrorValue(test.literal)
+		if !ok || info.Kind != test.kind {
+			t.Errorf("LookupErrorValue(%s) = %v, %v; want %v", test.literal, info.Kind, ok, test.kind)
+		}
+	}
+	for _, test := range tests {
+		text, err := test.kind.MarshalText()
+		if err != nil || string(text) != test.literal {
+			t.Errorf("MarshalText() of %s = %s, %v", test.literal, text, err)
+		}
+		var kind ErrorKind
+		if err := kind.UnmarshalText([]byte(strings.ToLower(test.literal))); err != nil || kind != test.kind {
+			t.Errorf("UnmarshalText(%s) = %v, %v; want %v", strings.ToLower(test.literal), kind, err, test.kind)
+		}
+	}
+	if _, err := ErrorKind(0).MarshalText(); err == nil {
+		t.Errorf("Expected error when marshaling ErrorKind(0)")
+	}
+	var kind ErrorKind
+	if err := kind.UnmarshalText([]byte("#OOPS!")); err == nil {
+		t.Errorf("Expected error when unmarshaling #OOPS!")
+	}
+	if ErrorKind(0).String() != "ErrorKind(0)" {
+		t.Errorf("Expected 'ErrorKind(0)', got '%s'", ErrorKind(0).String())
+	}
+}
+
+func TestRegisterErrorValue(t *testing.T) {
+	if _, err := RegisterErrorValue("#DIV/0!", 2); err == nil {
+		t.Errorf("Expected error when registering #DIV/0! twice")
+	}
+	if _, err := RegisterErrorValue("OOPS!", 99); err == nil {
+		t.Errorf("Expected error when registering a value without '#'")
+	}
+	kind, err := RegisterErrorValue("#TEST_ONLY!", 99)
+	if err != nil {
+		t.Fatalf("RegisterErrorValue: %v", err)
+	}
+	if kind.String() != "#TEST_ONLY!" || kind.Code() != 99 {
+		t.Errorf("Unexpected registered error value %s (%d)", kind.String(), kind.Code())
+	}
+	node, err := NewParser("=IFERROR(#TEST_ONLY!, 0)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var arg = node.(FunCallExpr).Arguments[0].(LiteralExpr)
+	if arg.ErrorKind != kind {
+		t.Errorf("Expected error kind %v, got %v", kind, arg.ErrorKind)
+	}
+}
@@ -33,3 +33,22 @@ func newParseError(pos Pos, format string, args ...interface{}) *ParseError {
 func (e *ParseError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
 }
+
+// EvalError is returned by Evaluate for formulas it cannot evaluate, e.g. a
+// call of a built-in function with too many arguments. Errors of the formula
+// itself, such as #DIV/0!, are error Values instead.
+type EvalError struct {
+	Pos     Pos
+	Message string
+}
+
+func newEvalError(pos Pos, format string, args ...interface{}) *EvalError {
+	return &EvalError{
+		Pos:     pos,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *EvalError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
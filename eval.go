@@ -0,0 +1,694 @@
+package excelformulaparser
+
+import (
+	"math"
+	"strings"
+)
+
+// A CellResolver provides the values of the cells that a formula refers to.
+type CellResolver interface {
+	// Cell returns the value of the cell at the zero-based row and column of
+	// a sheet, or the blank Value for an empty cell.
+	Cell(sheet string, row, col int) (Value, error)
+}
+
+// A UsedRangeResolver is a CellResolver that knows which cells of a sheet may
+// be non-blank. References that extend to the last row or column of a sheet,
+// such as A:A or 1:1, then only read the cells in the used range.
+type UsedRangeResolver interface {
+	CellResolver
+	// UsedRange returns the number of rows and columns from the top left cell
+	// of a sheet that contain all its non-blank cells.
+	UsedRange(sheet string) (rows, cols int, err error)
+}
+
+// EvalContext is the environment of an evaluated formula.
+type EvalContext struct {
+	Resolver CellResolver   // The values of referenced cells, nil if all cells are blank
+	Sheet    string         // The sheet of the formula, used for references without a sheet name
+	Row, Col int            // The zero-based cell of the formula, for relative R1C1 references and implicit intersection
+	Rand     func() float64 // The random numbers in [0, 1) of RAND and RANDBETWEEN, rand.Float64 if nil
+}
+
+// Evaluate computes the value of a formula. A reference in the result is
+// replaced by the cell values, like the value shown in the cell of the
+// formula: a single cell by its value, and an area by an array. Blank cells
+// become 0.
+//
+// Errors of the formula, such as a division by zero or an unknown function,
+// are returned as error Values (e.g., #DIV/0! or #NAME?). Evaluate returns an
+// error if the formula cannot be evaluated, e.g. for a reference to another
+// workbook, or if the Resolver fails.
+func Evaluate(node Node, ctx *EvalContext) (Value, error) {
+	if ctx == nil {
+		ctx = &EvalContext{}
+	}
+	var e = &evaluator{ctx: ctx}
+	v, err := e.eval(node, nil)
+	if err != nil {
+		return Value{}, err
+	}
+	if v, err = e.deref(v); err != nil {
+		return Value{}, err
+	}
+	return result(v), nil
+}
+
+// result returns the value of v shown in a cell.
+func result(v Value) Value {
+	switch v.Kind {
+	case ValueBlank:
+		return NewNumber(0)
+	case ValueLambda:
+		return NewError(ErrorCalc)
+	case ValueArray:
+		var rows = make([][]Value, len(v.Array))
+		for i, row := range v.Array {
+			rows[i] = make([]Value, len(row))
+			for j, element := range row {
+				rows[i][j] = result(element)
+			}
+		}
+		return NewArray(rows)
+	default:
+		return v
+	}
+}
+
+type evaluator struct {
+	ctx *EvalContext
+}
+
+// A scope binds the LET names and LAMBDA parameters in scope, innermost first.
+// Names are identified by their declaration tokens (see IdentExpr.Binding).
+type scope struct {
+	decl   *Token
+	value  Value
+	parent *scope
+}
+
+func (s *scope) bind(decl *Token, value Value) *scope {
+	return &scope{decl: decl, value: value, parent: s}
+}
+
+func (s *scope) lookup(decl *Token) (Value, bool) {
+	for ; s != nil; s = s.parent {
+		if s.decl == decl {
+			return s.value, true
+		}
+	}
+	return Value{}, false
+}
+
+// A closure is a LAMBDA function with the scope it was created in.
+type closure struct {
+	lambda LambdaExpr
+	scope  *scope
+}
+
+// A builtin evaluates a call of a built-in function.
+type builtin func(c *funcCall) (Value, error)
+
+// builtins are the built-in functions by upper case name.
+var builtins = map[string]builtin{}
+
+// A funcCall is a call of a built-in function.
+type funcCall struct {
+	e     *evaluator
+	scope *scope
+	node  FunCallExpr
+	args  []Node
+}
+
+func (e *evaluator) eval(node Node, s *scope) (Value, error) {
+	switch n := node.(type) {
+	case nil:
+		return Value{}, nil
+
+	case LiteralExpr:
+		return literal(n), nil
+
+	case CellExpr:
+		var area = Area{Sheet: e.ctx.Sheet, Row: n.Row, Col: n.Col, LastRow: n.Row, LastCol: n.Col}
+		if n.Row < 0 { // a full column reference
+			area.Row, area.LastRow = 0, MaxRows-1
+		}
+		if n.Col < 0 { // a full row reference
+			area.Col, area.LastCol = 0, MaxColumns-1
+		}
+		if area.LastRow >= MaxRows || area.LastCol >= MaxColumns {
+			return NewError(ErrorRef), nil
+		}
+		return NewReference(area), nil
+
+	case R1C1Expr:
+		row, lastRow, ok := r1c1Range(n.Row, e.ctx.Row, MaxRows)
+		if !ok {
+			return NewError(ErrorRef), nil
+		}
+		col, lastCol, ok := r1c1Range(n.Col, e.ctx.Col, MaxColumns)
+		if !ok {
+			return NewError(ErrorRef), nil
+		}
+		return NewReference(Area{Sheet: e.ctx.Sheet, Row: row, Col: col, LastRow: lastRow, LastCol: lastCol}), nil
+
+	case IdentExpr:
+		if n.Binding != nil {
+			if v, ok := s.lookup(n.Binding); ok {
+				return v, nil
+			}
+		}
+		switch strings.ToUpper(n.Name.Raw) {
+		case "TRUE":
+			return NewBool(true), nil
+		case "FALSE":
+			return NewBool(false), nil
+		}
+		return NewError(ErrorName), nil // defined names are not supported
+
+	case RangeExpr:
+		var parts = append([]Node{n.Begin}, n.Ends...)
+		var area Area
+		for i, part := range parts {
+			v, err := e.eval(part, s)
+			if err != nil || v.Kind == ValueError {
+				return v, err
+			}
+			if v.Kind != ValueReference || len(v.Areas) != 1 {
+				return NewError(ErrorValue), nil
+			}
+			var a = v.Areas[0]
+			if i == 0 {
+				area = a
+				continue
+			}
+			if a.Sheet != area.Sheet {
+				return NewError(ErrorValue), nil
+			}
+			area.Row, area.Col = min(area.Row, a.Row), min(area.Col, a.Col)
+			area.LastRow, area.LastCol = max(area.LastRow, a.LastRow), max(area.LastCol, a.LastCol)
+		}
+		return NewReference(area), nil
+
+	case SheetRefExpr:
+		v, err := e.eval(n.Ref, s)
+		if err != nil || v.Kind != ValueReference {
+			return v, err
+		}
+		var areas = make([]Area, len(v.Areas))
+		for i, area := range v.Areas {
+			area.Sheet = n.Sheet
+			areas[i] = area
+		}
+		return NewReference(areas...), nil
+
+	case Sheet3DRefExpr, ExternalRefExpr, StructuredRefExpr:
+		return NewError(ErrorRef), nil // 3D, external and structured references are not supported
+
+	case BinaryExpr:
+		return e.binary(n, s)
+
+	case UnaryExpr:
+		return e.unary(n, s)
+
+	case ParenthesizedExpr:
+		return e.eval(n.Inner, s)
+
+	case FunCallExpr:
+		f, ok := builtins[canonicalFunctionName(n.Name.Raw)]
+		if !ok {
+			return NewError(ErrorName), nil
+		}
+		return f(&funcCall{e: e, scope: s, node: n, args: n.Arguments})
+
+	case CallExpr:
+		callee, err := e.eval(n.Callee, s)
+		if err != nil || callee.Kind == ValueError {
+			return callee, err
+		}
+		if callee.Kind != ValueLambda {
+			return NewError(ErrorValue), nil
+		}
+		var args = make([]Value, len(n.Arguments))
+		for i, arg := range n.Arguments {
+			if args[i], err = e.eval(arg, s); err != nil {
+				return Value{}, err
+			}
+		}
+		return e.apply(callee.lambda, args)
+
+	case LetExpr:
+		for _, binding := range n.Bindings {
+			v, err := e.eval(binding.Value, s)
+			if err != nil {
+				return Value{}, err
+			}
+			s = s.bind(binding.Name, v)
+		}
+		return e.eval(n.Body, s)
+
+	case LambdaExpr:
+		return Value{Kind: ValueLambda, lambda: &closure{lambda: n, scope: s}}, nil
+
+	case EmptyArgExpr:
+		return Value{}, nil
+
+	case ArrayExpr:
+		var rows = make([][]Value, len(n.Elements))
+		for i, row := range n.Elements {
+			rows[i] = make([]Value, len(row))
+			for j, element := range row {
+				v, err := e.value(element, s)
+				if err != nil {
+					return Value{}, err
+				}
+				rows[i][j] = v
+			}
+		}
+		return NewArray(rows), nil
+
+	default:
+		return Value{}, newEvalError(node.Start(), "unexpected node type %T", n)
+	}
+}
+
+// value evaluates node, and replaces a reference by the cell values.
+func (e *evaluator) value(node Node, s *scope) (Value, error) {
+	v, err := e.eval(node, s)
+	if err != nil {
+		return Value{}, err
+	}
+	return e.deref(v)
+}
+
+// deref returns the value of a single cell reference, and an array of the cell
+// values for an area. Other values are returned as is.
+func (e *evaluator) deref(v Value) (Value, error) {
+	if v.Kind != ValueReference {
+		return v, nil
+	}
+	if len(v.Areas) != 1 {
+		return NewError(ErrorValue), nil
+	}
+	var area = v.Areas[0]
+	if area.Rows() == 1 && area.Cols() == 1 {
+		return e.cell(area.Sheet, area.Row, area.Col)
+	}
+	area, err := e.usedArea(area)
+	if err != nil {
+		return Value{}, err
+	}
+	var rows = make([][]Value, area.Rows())
+	for i := range rows {
+		rows[i] = make([]Value, area.Cols())
+		for j := range rows[i] {
+			cell, err := e.cell(area.Sheet, area.Row+i, area.Col+j)
+			if err != nil {
+				return Value{}, err
+			}
+			rows[i][j] = cell
+		}
+	}
+	return NewArray(rows), nil
+}
+
+// usedArea trims an area that extends to the last row or column of a sheet
+// (e.g., A:A) to the used range of the resolver, keeping at least one row and
+// column. All cells are blank without a resolver, and areas are not trimmed
+// if the resolver is not a UsedRangeResolver.
+func (e *evaluator) usedArea(area Area) (Area, error) {
+	var rows, cols int
+	switch r := e.ctx.Resolver.(type) {
+	case nil:
+	case UsedRangeResolver:
+		var err error
+		if rows, cols, err = r.UsedRange(area.Sheet); err != nil {
+			return Area{}, err
+		}
+	default:
+		return area, nil
+	}
+	if area.LastRow == MaxRows-1 {
+		area.LastRow = max(area.Row, min(area.LastRow, rows-1))
+	}
+	if area.LastCol == MaxColumns-1 {
+		area.LastCol = max(area.Col, min(area.LastCol, cols-1))
+	}
+	return area, nil
+}
+
+func (e *evaluator) cell(sheet string, row, col int) (Value, error) {
+	if e.ctx.Resolver == nil {
+		return Value{}, nil
+	}
+	return e.ctx.Resolver.Cell(sheet, row, col)
+}
+
+// apply calls a LAMBDA function.
+func (e *evaluator) apply(c *closure, args []Value) (Value, error) {
+	if len(args) != len(c.lambda.Params) {
+		return NewError(ErrorValue), nil
+	}
+	var s = c.scope
+	for i, param := range c.lambda.Params {
+		s = s.bind(param, args[i])
+	}
+	return e.eval(c.lambda.Body, s)
+}
+
+func (e *evaluator) binary(n BinaryExpr, s *scope) (Value, error) {
+	switch n.Operator.Type {
+	case Comma, Semicolon: // the union operator, e.g. (A1,C1)
+		left, right, err := e.references(n, s)
+		if err != nil || left.Kind != ValueReference || right.Kind != ValueReference {
+			return left, err
+		}
+		return NewReference(append(append([]Area{}, left.Areas...), right.Areas...)...), nil
+	case Intersection:
+		left, right, err := e.references(n, s)
+		if err != nil || left.Kind != ValueReference || right.Kind != ValueReference {
+			return left, err
+		}
+		var areas []Area
+		for _, a := range left.Areas {
+			for _, b := range right.Areas {
+				if area, ok := intersect(a, b); ok {
+					areas = append(areas, area)
+				}
+			}
+		}
+		if len(areas) == 0 {
+			return NewError(ErrorNull), nil
+		}
+		return NewReference(areas...), nil
+	}
+	left, err := e.value(n.Left, s)
+	if err != nil {
+		return Value{}, err
+	}
+	right, err := e.value(n.Right, s)
+	if err != nil {
+		return Value{}, err
+	}
+	return binaryOp(n.Operator.Type, left, right), nil
+}
+
+// references evaluates the operands of a reference operator. If an operand
+// is not a reference, left is the error value of the operation.
+func (e *evaluator) references(n BinaryExpr, s *scope) (left, right Value, err error) {
+	if left, err = e.eval(n.Left, s); err != nil {
+		return
+	}
+	if right, err = e.eval(n.Right, s); err != nil {
+		return
+	}
+	switch {
+	case left.Kind == ValueError:
+	case right.Kind == ValueError:
+		left = right
+	case left.Kind != ValueReference || right.Kind != ValueReference:
+		left = NewError(ErrorValue)
+	default:
+		return
+	}
+	return left, Value{}, nil
+}
+
+// intersect returns the cells in both a and b.
+func intersect(a, b Area) (Area, bool) {
+	var area = Area{
+		Sheet:   a.Sheet,
+		Row:     max(a.Row, b.Row),
+		Col:     max(a.Col, b.Col),
+		LastRow: min(a.LastRow, b.LastRow),
+		LastCol: min(a.LastCol, b.LastCol),
+	}
+	return area, a.Sheet == b.Sheet && area.Row <= area.LastRow && area.Col <= area.LastCol
+}
+
+func (e *evaluator) unary(n UnaryExpr, s *scope) (Value, error) {
+	switch n.Operator.Type {
+	case ImplicitIntersection:
+		v, err := e.eval(n.Operand, s)
+		if err != nil {
+			return Value{}, err
+		}
+		return e.implicitIntersection(v), nil
+	case Spill:
+		return NewError(ErrorRef), nil // spilled range references are not supported
+	}
+	v, err := e.value(n.Operand, s)
+	if err != nil {
+		return Value{}, err
+	}
+	return unaryOp(n.Operator.Type, v), nil
+}
+
+// implicitIntersection reduces a value to a single value: a reference to the
+// cell of an area in the row or column of the formula, or the first element of
+// an array.
+func (e *evaluator) implicitIntersection(v Value) Value {
+	switch v.Kind {
+	case ValueArray:
+		if len(v.Array) == 0 || len(v.Array[0]) == 0 {
+			return NewError(ErrorValue)
+		}
+		return v.Array[0][0]
+	case ValueReference:
+		if len(v.Areas) != 1 {
+			return NewError(ErrorValue)
+		}
+		var area = v.Areas[0]
+		switch {
+		case area.Rows() == 1 && area.Cols() == 1:
+			return v
+		case area.Cols() == 1 && e.ctx.Row >= area.Row && e.ctx.Row <= area.LastRow:
+			area.Row, area.LastRow = e.ctx.Row, e.ctx.Row
+		case area.Rows() == 1 && e.ctx.Col >= area.Col && e.ctx.Col <= area.LastCol:
+			area.Col, area.LastCol = e.ctx.Col, e.ctx.Col
+		default:
+			return NewError(ErrorValue)
+		}
+		return NewReference(area)
+	default:
+		return v
+	}
+}
+
+// binaryOp applies an arithmetic, text or comparison operator to values,
+// element-wise if one of them is an array.
+func binaryOp(op TokenType, left, right Value) Value {
+	if left.Kind == ValueArray || right.Kind == ValueArray {
+		return broadcast(left, right, func(a, b Value) Value {
+			return binaryOp(op, a, b)
+		})
+	}
+	if left.Kind == ValueError {
+		return left
+	}
+	switch op {
+	case Concat, Equal, NotEqual, LessThan, GreaterThan, LessThanOrEqual, GreaterThanOrEqual:
+		if right.Kind == ValueError {
+			return right
+		}
+	}
+	switch op {
+	case Concat:
+		return NewString(toText(left) + toText(right))
+	case Equal, NotEqual, LessThan, GreaterThan, LessThanOrEqual, GreaterThanOrEqual:
+		var c = compare(left, right)
+		switch op {
+		case Equal:
+			return NewBool(c == 0)
+		case NotEqual:
+			return NewBool(c != 0)
+		case LessThan:
+			return NewBool(c < 0)
+		case GreaterThan:
+			return NewBool(c > 0)
+		case LessThanOrEqual:
+			return NewBool(c <= 0)
+		default:
+			return NewBool(c >= 0)
+		}
+	}
+	x, err := toNumber(left) // before the right operand, so "a"+#N/A is #VALUE!
+	if err != 0 {
+		return NewError(err)
+	}
+	y, err := toNumber(right)
+	if err != 0 {
+		return NewError(err)
+	}
+	switch op {
+	case Plus:
+		return finite(x + y)
+	case Minus:
+		return finite(x - y)
+	case Multiply:
+		return finite(x * y)
+	case Divide:
+		if y == 0 {
+			return NewError(ErrorDiv0)
+		}
+		return finite(x / y)
+	case Exponentiation:
+		if x == 0 && y == 0 {
+			return NewError(ErrorNum)
+		}
+		if x == 0 && y < 0 {
+			return NewError(ErrorDiv0)
+		}
+		return finite(math.Pow(x, y))
+	default:
+		return NewError(ErrorValue)
+	}
+}
+
+// unaryOp applies a prefix or postfix operator to a value, element-wise for arrays.
+func unaryOp(op TokenType, v Value) Value {
+	switch {
+	case v.Kind == ValueArray:
+		return broadcast(v, v, func(a, _ Value) Value {
+			return unaryOp(op, a)
+		})
+	case op == Plus:
+		return v // the unary plus does not convert its operand
+	case v.Kind == ValueError:
+		return v
+	}
+	x, err := toNumber(v)
+	if err != 0 {
+		return NewError(err)
+	}
+	switch op {
+	case Minus:
+		return finite(-x)
+	case Percent:
+		return finite(x / 100)
+	default:
+		return NewError(ErrorValue)
+	}
+}
+
+// broadcast applies f to the elements of two arrays of the same size. An array
+// with a single row or column, or a single value, is repeated to the size of
+// the other array; elements missing in a smaller array are #N/A.
+func broadcast(left, right Value, f func(a, b Value) Value) Value {
+	return lift([]Value{left, right}, func(v []Value) Value {
+		return f(v[0], v[1])
+	})
+}
+
+// lift applies f to the corresponding elements of values, like broadcast does
+// for two values. If none of them is an array, lift returns f(values).
+func lift(values []Value, f func(v []Value) Value) Value {
+	var rows, cols = 0, 0
+	var array = false
+	for _, v := range values {
+		rows, cols = max(rows, arrayRows(v)), max(cols, arrayCols(v))
+		array = array || v.Kind == ValueArray
+	}
+	if !array {
+		return f(values)
+	}
+	var result = make([][]Value, rows)
+	for i := range result {
+		result[i] = make([]Value, cols)
+	next:
+		for j := range result[i] {
+			var elements = make([]Value, len(values))
+			for k, v := range values {
+				var ok bool
+				if elements[k], ok = arrayElement(v, i, j); !ok {
+					result[i][j] = NewError(ErrorNA)
+					continue next
+				}
+			}
+			result[i][j] = f(elements)
+		}
+	}
+	return NewArray(result)
+}
+
+func arrayRows(v Value) int {
+	if v.Kind != ValueArray {
+		return 1
+	}
+	return len(v.Array)
+}
+
+func arrayCols(v Value) int {
+	if v.Kind != ValueArray || len(v.Array) == 0 {
+		return 1
+	}
+	return len(v.Array[0])
+}
+
+// arrayElement returns the element of v at row i and column j, repeating a
+// single row or column.
+func arrayElement(v Value, i, j int) (Value, bool) {
+	if v.Kind != ValueArray {
+		return v, true
+	}
+	if len(v.Array) == 1 {
+		i = 0
+	}
+	if i >= len(v.Array) {
+		return Value{}, false
+	}
+	var row = v.Array[i]
+	if len(row) == 1 {
+		j = 0
+	}
+	if j >= len(row) {
+		return Value{}, false
+	}
+	return row[j], true
+}
+
+// finite returns a number value, or #NUM! if x is not a finite number.
+func finite(x float64) Value {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return NewError(ErrorNum)
+	}
+	return NewNumber(x)
+}
+
+// literal returns the value of a literal.
+func literal(n LiteralExpr) Value {
+	switch n.Value.Type {
+	case Number:
+		return NewNumber(n.Number)
+	case String:
+		return NewString(unquoteString(n.Value.Raw))
+	case BoolLiteral:
+		return NewBool(strings.EqualFold(n.Value.Raw, "TRUE"))
+	case EValue:
+		return NewError(n.ErrorKind)
+	default:
+		return NewError(ErrorValue)
+	}
+}
+
+// unquoteString returns the text of a string literal, e.g. a"b for "a""b".
+func unquoteString(raw string) string {
+	if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+		raw = raw[1 : len(raw)-1]
+	}
+	return strings.ReplaceAll(raw, `""`, `"`)
+}
+
+// r1c1Range returns the first and last index of an R1C1 row or column part
+// relative to base, and whether they are within max.
+func r1c1Range(i R1C1Index, base, max int) (first, last int, ok bool) {
+	switch {
+	case i.Omitted:
+		return 0, max - 1, true
+	case i.Relative:
+		first = base + i.Value
+	default:
+		first = i.Value
+	}
+	return first, first, first >= 0 && first < max
+}
@@ -0,0 +1,291 @@
+package excelformulaparser
+
+import (
+	"errors"
+	"testing"
+)
+
+type cellKey struct {
+	sheet    string
+	row, col int
+}
+
+// cells is a CellResolver of cells written in A1 notation, e.g. "A1" or "Sheet2!B3".
+type cells map[cellKey]Value
+
+func newCells(values map[string]Value) cells {
+	var c = cells{}
+	for ref, v := range values {
+		var sheet = ""
+		for i := range ref {
+			if ref[i] == '!' {
+				sheet, ref = ref[:i], ref[i+1:]
+				break
+			}
+		}
+		cell, err := parseCell(ref)
+		if err != nil {
+			panic(err)
+		}
+		c[cellKey{sheet, cell.row, cell.col}] = v
+	}
+	return c
+}
+
+func (c cells) Cell(sheet string, row, col int) (Value, error) {
+	return c[cellKey{sheet, row, col}], nil
+}
+
+var testCells = newCells(map[string]Value{
+	"A1":        NewNumber(1),
+	"A2":        NewNumber(2),
+	"A3":        NewNumber(3),
+	"B1":        NewNumber(10),
+	"B2":        NewString("x"),
+	"B3":        NewBool(true),
+	"Sheet2!A1": NewNumber(100),
+})
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"=1+2*3", "7"},
+		{"=(1+2)*3", "9"},
+		{"=2^3^2", "64"},
+		{"=-2^2", "4"},
+		{"=-(2^2)", "-4"},
+		{"=10%", "0.1"},
+		{"=1/3", "0.333333333333333"},
+		{`="a"&"b"&1`, `"ab1"`},
+		{`="a""b"`, `"a""b"`},
+		{"=1/0", "#DIV/0!"},
+		{"=#N/A+1", "#N/A"},
+		{"=0^0", "#NUM!"},
+		{"=A1+B1", "11"},
+		{"=a2*b1", "20"},
+		{"=C1", "0"},
+		{"=B2", `"x"`},
+		{"=B2&C1", `"x"`},
+		{"=B3+1", "2"},
+		{"=B2+1", "#VALUE!"},
+		{"=Sheet2!A1+A1", "101"},
+		{"=A1:B1*2", "{2,20}"},
+		{"=A1:A3", "{1;2;3}"},
+		{"=A1:A2:B1", "{1,10;2,\"x\"}"},
+		{"={1,2}+{10;20}", "{11,12;21,22}"},
+		{"={1,2,3}*{1,2}", "{1,4,#N/A}"},
+		{"=-{1,2}", "{-1,-2}"},
+		{"=A1:B1 B1:B3", "10"},
+		{"=A1:B2 (B1:C2)", "{10;\"x\"}"},
+		{"=A1 (A1:B1)", "1"},
+		{"=A1:A2 B1:B2", "#NULL!"},
+		{"=(A1,B1)", "#VALUE!"},
+		{"=@A1:A3", "2"},
+		{"=@B1:B3", "\"x\""},
+		{"=@A1:B2", "#VALUE!"},
+		{"=1<\"a\"", "TRUE"},
+		{"=\"a\"<TRUE", "TRUE"},
+		{`="A"="a"`, "TRUE"},
+		{"=C1=0", "TRUE"},
+		{`=C1=""`, "TRUE"},
+		{"=2>=3", "FALSE"},
+		{"=true", "TRUE"},
+		{"=LET(x, 2, y, x*3, x+y)", "8"},
+		{"=LAMBDA(a, b, a*b)(3, 4)", "12"},
+		{"=LET(f, LAMBDA(x, x+1), f(f(1)))", "3"},
+		{"=LET(x, 1, f, LAMBDA(y, x+y), x+f(10))", "12"},
+		{"=LAMBDA(x, x)(1, 2)", "#VALUE!"},
+		{"=LAMBDA(x, x)", "#CALC!"},
+		{"=UNKNOWN(1)", "#NAME?"},
+		{"=MyName", "#NAME?"},
+		{"=NAME1", "#NAME?"}, // a name, as column NAME is after XFD
+		{"=[1]Sheet1!A1", "#REF!"},
+		{"=1+Jan:Dec!B2", "#REF!"},
+		{"=Table1[Col]", "#REF!"},
+		{"=A1#", "#REF!"},
+		{"=IFERROR([1]Sheet1!A1, 0)", "0"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		v, err := Evaluate(node, &EvalContext{Resolver: testCells, Row: 1, Col: 1})
+		if err != nil {
+			t.Errorf("For input '%s', unexpected error: %v", test.src, err)
+			continue
+		}
+		if got := v.String(); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+	}
+}
+
+func TestEvaluateR1C1(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"=R1C1+R[-1]C[-1]", "2"},
+		{"=SUM(R[-2]C)", "#REF!"},
+		{"=SUM(R[-1]C:R[1]C)", "10"},
+		{"=RC[-1]:R[1]C[-1]", "{2;3}"},
+		{"=R[-5]C", "#REF!"},
+	}
+	for _, test := range tests {
+		node, err := NewParserWithOptions(test.src, ParseOptions{R1C1: true}).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		v, err := Evaluate(node, &EvalContext{Resolver: testCells, Row: 1, Col: 1})
+		if err != nil {
+			t.Errorf("For input '%s', unexpected error: %v", test.src, err)
+			continue
+		}
+		if got := v.String(); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+	}
+}
+
+// usedCells is a UsedRangeResolver of cells that counts the cells it reads.
+type usedCells struct {
+	cells
+	reads int
+}
+
+func (c *usedCells) Cell(sheet string, row, col int) (Value, error) {
+	c.reads++
+	return c.cells.Cell(sheet, row, col)
+}
+
+func (c *usedCells) UsedRange(sheet string) (rows, cols int, err error) {
+	for key := range c.cells {
+		if key.sheet == sheet {
+			rows, cols = max(rows, key.row+1), max(cols, key.col+1)
+		}
+	}
+	return rows, cols, nil
+}
+
+func TestEvaluateUsedRange(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"=SUM(A:A)", "6"},
+		{"=SUM(1:1)", "11"},
+		{"=SUM(A1:XFD1048576)", "16"},
+		{"=SUM(B2:B1048576)", "0"},
+		{"=SUM(Sheet2!A:B)", "100"},
+		{"=A:A", "{1;2;3}"},
+		{"=A2:B1048576", "{2,\"x\";3,TRUE}"},
+		{"=C:C", "{0;0;0}"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		var resolver = &usedCells{cells: testCells}
+		v, err := Evaluate(node, &EvalContext{Resolver: resolver})
+		if err != nil {
+			t.Errorf("For input '%s', unexpected error: %v", test.src, err)
+			continue
+		}
+		if got := v.String(); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+		if resolver.reads > 6 {
+			t.Errorf("For input '%s', expected at most 6 cells to be read, got %d", test.src, resolver.reads)
+		}
+	}
+
+	for src, expected := range map[string]string{"=SUM(A:J)": "0", "=SUM(A1:XFD1048576)": "0", "=1:1": "{0}"} {
+		node, err := NewParser(src).Parse()
+		if err != nil {
+			t.Fatalf("Parse error for '%s': %v", src, err)
+		}
+		if v, err := Evaluate(node, nil); err != nil || v.String() != expected {
+			t.Errorf("For input '%s' without a resolver, expected '%s', got %v, %v", src, expected, v, err)
+		}
+	}
+}
+
+type failingResolver struct{}
+
+var errResolver = errors.New("resolver failed")
+
+func (failingResolver) Cell(sheet string, row, col int) (Value, error) {
+	return Value{}, errResolver
+}
+
+func TestEvaluateError(t *testing.T) {
+	node, err := NewParser("=1+A1").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if _, err := Evaluate(node, &EvalContext{Resolver: failingResolver{}}); err != errResolver {
+		t.Errorf("Expected the resolver error, got %v", err)
+	}
+	if v, err := Evaluate(node, nil); err != nil || v.String() != "1" {
+		t.Errorf("Expected blank cells without a resolver, got %v, %v", v, err)
+	}
+}
+
+func TestEvaluateErrorPrecedence(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"=#DIV/0!+#N/A", "#DIV/0!"},
+		{"=#N/A+#DIV/0!", "#N/A"},
+		{"=1/0+#N/A", "#DIV/0!"},
+		{"=#N/A*(1/0)", "#N/A"},
+		{"=#N/A&#REF!", "#N/A"},
+		{`="a"&#REF!`, "#REF!"},
+		{"=#NUM!=#NUM!", "#NUM!"},
+		{"=#NULL!<#N/A", "#NULL!"},
+		{`="a"+#N/A`, "#VALUE!"},
+		{`=#N/A+"a"`, "#N/A"},
+		{`=1+"a"&#REF!`, "#VALUE!"},
+		{"=2^#NAME?", "#NAME?"},
+		{"=-#REF!", "#REF!"},
+		{"=#NAME?%", "#NAME?"},
+		{"=+#N/A", "#N/A"},
+		{"={1,#N/A}+{#DIV/0!,2}", "{#DIV/0!,#N/A}"},
+		{"=(A1:A2 B1:B2)+1/0", "#NULL!"},
+		{"=UNKNOWN(1/0)", "#NAME?"},
+		{"=LAMBDA(x, x+1)(#N/A)", "#N/A"},
+		{"=LET(x, 1/0, 1)", "1"},
+		{"=LET(x, 1/0, x+#N/A)", "#DIV/0!"},
+		{"=IF(TRUE, 1, 1/0)", "1"},
+		{"=IF(1/0, 1, #N/A)", "#DIV/0!"},
+		{"=IFERROR(#N/A+1/0, 0)", "0"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		v, err := Evaluate(node, &EvalContext{Resolver: testCells})
+		if err != nil {
+			t.Errorf("For input '%s', unexpected error: %v", test.src, err)
+			continue
+		}
+		if got := v.String(); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+		if v.Kind == ValueError {
+			if kind, _ := LookupErrorValue(v.String()); kind.Kind != v.Error {
+				t.Errorf("For input '%s', %s does not round-trip", test.src, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package excelformulaparser
+
+// functionNamesDE maps German function names to their English names.
+var functionNamesDE = map[string]string{
+	"SUMME":               "SUM",
+	"MITTELWERT":          "AVERAGE",
+	"ANZAHL":              "COUNT",
+	"ANZAHL2":             "COUNTA",
+	"ZÄHLENWENN":          "COUNTIF",
+	"SUMMEWENN":           "SUMIF",
+	"WENN":                "IF",
+	"WENNFEHLER":          "IFERROR",
+	"UND":                 "AND",
+	"ODER":                "OR",
+	"NICHT":               "NOT",
+	"SVERWEIS":            "VLOOKUP",
+	"WVERWEIS":            "HLOOKUP",
+	"VERGLEICH":           "MATCH",
+	"RUNDEN":              "ROUND",
+	"AUFRUNDEN":           "ROUNDUP",
+	"ABRUNDEN":            "ROUNDDOWN",
+	"LINKS":               "LEFT",
+	"RECHTS":              "RIGHT",
+	"TEIL":                "MID",
+	"LÄNGE":               "LEN",
+	"VERKETTEN":           "CONCATENATE",
+	"HEUTE":               "TODAY",
+	"JETZT":               "NOW",
+	"DATUM":               "DATE",
+	"JAHR":                "YEAR",
+	"MONAT":               "MONTH",
+	"TAG":                 "DAY",
+	"GANZZAHL":            "INT",
+	"REST":                "MOD",
+	"GROSS":               "UPPER",
+	"KLEIN":               "LOWER",
+	"GLÄTTEN":             "TRIM",
+	"WECHSELN":            "SUBSTITUTE",
+	"WERT":                "VALUE",
+	"PRODUKT":             "PRODUCT",
+	"WURZEL":              "SQRT",
+	"POTENZ":              "POWER",
+	"ISTLEER":             "ISBLANK",
+	"ISTFEHLER":           "ISERROR",
+	"ISTZAHL":             "ISNUMBER",
+	"SUMMENPRODUKT":       "SUMPRODUCT",
+	"FINDEN":              "FIND",
+	"SUCHEN":              "SEARCH",
+	"ERSETZEN":            "REPLACE",
+	"KGRÖSSTE":            "LARGE",
+	"KKLEINSTE":           "SMALL",
+	"BEREICH.VERSCHIEBEN": "OFFSET",
+	"INDIREKT":            "INDIRECT",
+	"WAHL":                "CHOOSE",
+}
+
+// functionNamesFR maps French function names to their English names.
+var functionNamesFR = map[string]string{
+	"SOMME":         "SUM",
+	"MOYENNE":       "AVERAGE",
+	"NB":            "COUNT",
+	"NBVAL":         "COUNTA",
+	"NB.SI":         "COUNTIF",
+	"SOMME.SI":      "SUMIF",
+	"SI":            "IF",
+	"SIERREUR":      "IFERROR",
+	"ET":            "AND",
+	"OU":            "OR",
+	"NON":           "NOT",
+	"RECHERCHEV":    "VLOOKUP",
+	"RECHERCHEH":    "HLOOKUP",
+	"EQUIV":         "MATCH",
+	"ARRONDI":       "ROUND",
+	"ARRONDI.SUP":   "ROUNDUP",
+	"ARRONDI.INF":   "ROUNDDOWN",
+	"GAUCHE":        "LEFT",
+	"DROITE":        "RIGHT",
+	"STXT":          "MID",
+	"NBCAR":         "LEN",
+	"CONCATENER":    "CONCATENATE",
+	"AUJOURDHUI":    "TODAY",
+	"MAINTENANT":    "NOW",
+	"ANNEE":         "YEAR",
+	"MOIS":          "MONTH",
+	"JOUR":          "DAY",
+	"ENT":           "INT",
+	"MAJUSCULE":     "UPPER",
+	"MINUSCULE":     "LOWER",
+	"SUPPRESPACE":   "TRIM",
+	"SUBSTITUE":     "SUBSTITUTE",
+	"TEXTE":         "TEXT",
+	"CNUM":          "VALUE",
+	"PRODUIT":       "PRODUCT",
+	"RACINE":        "SQRT",
+	"PUISSANCE":     "POWER",
+	"ESTVIDE":       "ISBLANK",
+	"ESTERREUR":     "ISERROR",
+	"ESTNUM":        "ISNUMBER",
+	"SOMMEPROD":     "SUMPRODUCT",
+	"TROUVE":        "FIND",
+	"CHERCHE":       "SEARCH",
+	"REMPLACER":     "REPLACE",
+	"GRANDE.VALEUR": "LARGE",
+	"PETITE.VALEUR": "SMALL",
+	"DECALER":       "OFFSET",
+	"CHOISIR":       "CHOOSE",
+}
+
+// functionNamesES maps Spanish function names to their English names.
+var functionNamesES = map[string]string{
+	"SUMA":            "SUM",
+	"PROMEDIO":        "AVERAGE",
+	"CONTAR":          "COUNT",
+	"CONTARA":         "COUNTA",
+	"CONTAR.SI":       "COUNTIF",
+	"SUMAR.SI":        "SUMIF",
+	"SI":              "IF",
+	"SI.ERROR":        "IFERROR",
+	"Y":               "AND",
+	"O":               "OR",
+	"NO":              "NOT",
+	"BUSCARV":         "VLOOKUP",
+	"BUSCARH":         "HLOOKUP",
+	"INDICE":          "INDEX",
+	"COINCIDIR":       "MATCH",
+	"REDONDEAR":       "ROUND",
+	"REDONDEAR.MAS":   "ROUNDUP",
+	"REDONDEAR.MENOS": "ROUNDDOWN",
+	"IZQUIERDA":       "LEFT",
+	"DERECHA":         "RIGHT",
+	"EXTRAE":          "MID",
+	"LARGO":           "LEN",
+	"CONCATENAR":      "CONCATENATE",
+	"HOY":             "TODAY",
+	"AHORA":           "NOW",
+	"FECHA":           "DATE",
+	"AÑO":             "YEAR",
+	"MES":             "MONTH",
+	"DIA":             "DAY",
+	"ENTERO":          "INT",
+	"RESIDUO":         "MOD",
+	"MAYUSC":          "UPPER",
+	"MINUSC":          "LOWER",
+	"ESPACIOS":        "TRIM",
+	"SUSTITUIR":       "SUBSTITUTE",
+	"TEXTO":           "TEXT",
+	"VALOR":           "VALUE",
+	"PRODUCTO":        "PRODUCT",
+	"RAIZ":            "SQRT",
+	"POTENCIA":        "POWER",
+	"ESBLANCO":        "ISBLANK",
+	"ESERROR":         "ISERROR",
+	"ESNUMERO":        "ISNUMBER",
+	"SUMAPRODUCTO":    "SUMPRODUCT",
+	"ENCONTRAR":       "FIND",
+	"HALLAR":          "SEARCH",
+	"REEMPLAZAR":      "REPLACE",
+	"K.ESIMO.MAYOR":   "LARGE",
+	"K.ESIMO.MENOR":   "SMALL",
+	"DESREF":          "OFFSET",
+	"INDIRECTO":       "INDIRECT",
+	"ELEGIR":          "CHOOSE",
+}
+
+// functionNamesIT maps Italian function names to their English names.
+var functionNamesIT = map[string]string{
+	"SOMMA":               "SUM",
+	"MEDIA":               "AVERAGE",
+	"CONTA.NUMERI":        "COUNT",
+	"CONTA.VALORI":        "COUNTA",
+	"CONTA.SE":            "COUNTIF",
+	"SOMMA.SE":            "SUMIF",
+	"SE":                  "IF",
+	"SE.ERRORE":           "IFERROR",
+	"E":                   "AND",
+	"O":                   "OR",
+	"NON":                 "NOT",
+	"CERCA.VERT":          "VLOOKUP",
+	"CERCA.ORIZZ":         "HLOOKUP",
+	"INDICE":              "INDEX",
+	"CONFRONTA":           "MATCH",
+	"ARROTONDA":           "ROUND",
+	"ARROTONDA.PER.ECC":   "ROUNDUP",
+	"ARROTONDA.PER.DIF":   "ROUNDDOWN",
+	"SINISTRA":            "LEFT",
+	"DESTRA":              "RIGHT",
+	"STRINGA.ESTRAI":      "MID",
+	"LUNGHEZZA":           "LEN",
+	"CONCATENA":           "CONCATENATE",
+	"OGGI":                "TODAY",
+	"ADESSO":              "NOW",
+	"DATA":                "DATE",
+	"ANNO":                "YEAR",
+	"MESE":                "MONTH",
+	"GIORNO":              "DAY",
+	"ASS":                 "ABS",
+	"RESTO":               "MOD",
+	"MAIUSC":              "UPPER",
+	"MINUSC":              "LOWER",
+	"ANNULLA.SPAZI":       "TRIM",
+	"SOSTITUISCI":         "SUBSTITUTE",
+	"TESTO":               "TEXT",
+	"VALORE":              "VALUE",
+	"PRODOTTO":            "PRODUCT",
+	"RADQ":                "SQRT",
+	"POTENZA":             "POWER",
+	"VAL.VUOTO":           "ISBLANK",
+	"VAL.ERRORE":          "ISERROR",
+	"VAL.NUMERO":          "ISNUMBER",
+	"MATR.SOMMA.PRODOTTO": "SUMPRODUCT",
+	"TROVA":               "FIND",
+	"RICERCA":             "SEARCH",
+	"RIMPIAZZA":           "REPLACE",
+	"GRANDE":              "LARGE",
+	"PICCOLO":             "SMALL",
+	"SCARTO":              "OFFSET",
+	"INDIRETTO":           "INDIRECT",
+	"SCEGLI":              "CHOOSE",
+}
+
+// functionNamesNL maps Dutch function names to their English names.
+var functionNamesNL = map[string]string{
+	"SOM":                   "SUM",
+	"GEMIDDELDE":            "AVERAGE",
+	"AANTAL":                "COUNT",
+	"AANTALARG":             "COUNTA",
+	"AANTAL.ALS":            "COUNTIF",
+	"SOM.ALS":               "SUMIF",
+	"ALS":                   "IF",
+	"ALS.FOUT":              "IFERROR",
+	"EN":                    "AND",
+	"OF":                    "OR",
+	"NIET":                  "NOT",
+	"VERT.ZOEKEN":           "VLOOKUP",
+	"HORIZ.ZOEKEN":          "HLOOKUP",
+	"VERGELIJKEN":           "MATCH",
+	"AFRONDEN":              "ROUND",
+	"AFRONDEN.NAAR.BOVEN":   "ROUNDUP",
+	"AFRONDEN.NAAR.BENEDEN": "ROUNDDOWN",
+	"LINKS":                 "LEFT",
+	"RECHTS":                "RIGHT",
+	"DEEL":                  "MID",
+	"LENGTE":                "LEN",
+	"TEKST.SAMENVOEGEN":     "CONCATENATE",
+	"VANDAAG":               "TODAY",
+	"NU":                    "NOW",
+	"DATUM":                 "DATE",
+	"JAAR":                  "YEAR",
+	"MAAND":                 "MONTH",
+	"DAG":                   "DAY",
+	"INTEGER":               "INT",
+	"REST":                  "MOD",
+	"HOOFDLETTERS":          "UPPER",
+	"KLEINE.LETTERS":        "LOWER",
+	"SPATIES.WISSEN":        "TRIM",
+	"SUBSTITUEREN":          "SUBSTITUTE",
+	"TEKST":                 "TEXT",
+	"WAARDE":                "VALUE",
+	"WORTEL":                "SQRT",
+	"MACHT":                 "POWER",
+	"ISLEEG":                "ISBLANK",
+	"ISFOUT":                "ISERROR",
+	"ISGETAL":               "ISNUMBER",
+	"SOMPRODUCT":            "SUMPRODUCT",
+	"VIND.ALLES":            "FIND",
+	"VIND.SPEC":             "SEARCH",
+	"VERVANGEN":             "REPLACE",
+	"GROOTSTE":              "LARGE",
+	"KLEINSTE":              "SMALL",
+	"VERSCHUIVING":          "OFFSET",
+	"KIEZEN":                "CHOOSE",
+}
+
+// functionNamesPT maps Brazilian Portuguese function names to their English names.
+var functionNamesPT = map[string]string{
+	"SOMA":                  "SUM",
+	"MÉDIA":                 "AVERAGE",
+	"CONT.NÚM":              "COUNT",
+	"CONT.VALORES":          "COUNTA",
+	"CONT.SE":               "COUNTIF",
+	"SOMASE":                "SUMIF",
+	"MÍNIMO":                "MIN",
+	"MÁXIMO":                "MAX",
+	"SE":                    "IF",
+	"SEERRO":                "IFERROR",
+	"E":                     "AND",
+	"OU":                    "OR",
+	"NÃO":                   "NOT",
+	"PROCV":                 "VLOOKUP",
+	"PROCH":                 "HLOOKUP",
+	"ÍNDICE":                "INDEX",
+	"CORRESP":               "MATCH",
+	"ARRED":                 "ROUND",
+	"ARREDONDAR.PARA.CIMA":  "ROUNDUP",
+	"ARREDONDAR.PARA.BAIXO": "ROUNDDOWN",
+	"ESQUERDA":              "LEFT",
+	"DIREITA":               "RIGHT",
+	"EXT.TEXTO":             "MID",
+	"NÚM.CARACT":            "LEN",
+	"CONCATENAR":            "CONCATENATE",
+	"HOJE":                  "TODAY",
+	"AGORA":                 "NOW",
+	"DATA":                  "DATE",
+	"ANO":                   "YEAR",
+	"MÊS":                   "MONTH",
+	"DIA":                   "DAY",
+	"MAIÚSCULA":             "UPPER",
+	"MINÚSCULA":             "LOWER",
+	"ARRUMAR":               "TRIM",
+	"SUBSTITUIR":            "SUBSTITUTE",
+	"TEXTO":                 "TEXT",
+	"VALOR":                 "VALUE",
+	"MULT":                  "PRODUCT",
+	"RAIZ":                  "SQRT",
+	"POTÊNCIA":              "POWER",
+	"ÉCÉL.VAZIA":            "ISBLANK",
+	"ÉERROS":                "ISERROR",
+	"ÉNÚM":                  "ISNUMBER",
+	"SOMARPRODUTO":          "SUMPRODUCT",
+	"PROCURAR":              "FIND",
+	"LOCALIZAR":             "SEARCH",
+	"MUDAR":                 "REPLACE",
+	"MAIOR":                 "LARGE",
+	"MENOR":                 "SMALL",
+	"DESLOC":                "OFFSET",
+	"INDIRETO":              "INDIRECT",
+	"ESCOLHER":              "CHOOSE",
+}
+
+// functionNamesRU maps Russian function names to their English names.
+var functionNamesRU = map[string]string{
+	"СУММ":        "SUM",
+	"СРЗНАЧ":      "AVERAGE",
+	"СЧЁТ":        "COUNT",
+	"СЧЁТЗ":       "COUNTA",
+	"СЧЁТЕСЛИ":    "COUNTIF",
+	"СУММЕСЛИ":    "SUMIF",
+	"МИН":         "MIN",
+	"МАКС":        "MAX",
+	"ЕСЛИ":        "IF",
+	"ЕСЛИОШИБКА":  "IFERROR",
+	"И":           "AND",
+	"ИЛИ":         "OR",
+	"НЕ":          "NOT",
+	"ВПР":         "VLOOKUP",
+	"ГПР":         "HLOOKUP",
+	"ИНДЕКС":      "INDEX",
+	"ПОИСКПОЗ":    "MATCH",
+	"ОКРУГЛ":      "ROUND",
+	"ОКРУГЛВВЕРХ": "ROUNDUP",
+	"ОКРУГЛВНИЗ":  "ROUNDDOWN",
+	"ЛЕВСИМВ":     "LEFT",
+	"ПРАВСИМВ":    "RIGHT",
+	"ПСТР":        "MID",
+	"ДЛСТР":       "LEN",
+	"СЦЕПИТЬ":     "CONCATENATE",
+	"СЕГОДНЯ":     "TODAY",
+	"ТДАТА":       "NOW",
+	"ДАТА":        "DATE",
+	"ГОД":         "YEAR",
+	"МЕСЯЦ":       "MONTH",
+	"ДЕНЬ":        "DAY",
+	"ЦЕЛОЕ":       "INT",
+	"ОСТАТ":       "MOD",
+	"ПРОПИСН":     "UPPER",
+	"СТРОЧН":      "LOWER",
+	"СЖПРОБЕЛЫ":   "TRIM",
+	"ПОДСТАВИТЬ":  "SUBSTITUTE",
+	"ТЕКСТ":       "TEXT",
+	"ЗНАЧЕН":      "VALUE",
+	"ПРОИЗВЕД":    "PRODUCT",
+	"КОРЕНЬ":      "SQRT",
+	"СТЕПЕНЬ":     "POWER",
+	"ЕПУСТО":      "ISBLANK",
+	"ЕОШИБКА":     "ISERROR",
+	"ЕЧИСЛО":      "ISNUMBER",
+	"СУММПРОИЗВ":  "SUMPRODUCT",
+	"НАЙТИ":       "FIND",
+	"ПОИСК":       "SEARCH",
+	"ЗАМЕНИТЬ":    "REPLACE",
+	"НАИБОЛЬШИЙ":  "LARGE",
+	"НАИМЕНЬШИЙ":  "SMALL",
+	"СМЕЩ":        "OFFSET",
+	"ДВССЫЛ":      "INDIRECT",
+	"ВЫБОР":       "CHOOSE",
+}
+
+// booleanNamesDE maps the German TRUE and FALSE to their English names.
+var booleanNamesDE = map[string]string{"WAHR": "TRUE", "FALSCH": "FALSE"}
+
+// booleanNamesFR maps the French TRUE and FALSE to their English names.
+var booleanNamesFR = map[string]string{"VRAI": "TRUE", "FAUX": "FALSE"}
+
+// booleanNamesES maps the Spanish TRUE and FALSE to their English names.
+var booleanNamesES = map[string]string{"VERDADERO": "TRUE", "FALSO": "FALSE"}
+
+// booleanNamesIT maps the Italian TRUE and FALSE to their English names.
+var booleanNamesIT = map[string]string{"VERO": "TRUE", "FALSO": "FALSE"}
+
+// booleanNamesNL maps the Dutch TRUE and FALSE to their English names.
+var booleanNamesNL = map[string]string{"WAAR": "TRUE", "ONWAAR": "FALSE"}
+
+// booleanNamesPT maps the Brazilian Portuguese TRUE and FALSE to their English names.
+var booleanNamesPT = map[string]string{"VERDADEIRO": "TRUE", "FALSO": "FALSE"}
+
+// booleanNamesRU maps the Russian TRUE and FALSE to their English names.
+var booleanNamesRU = map[string]string{"ИСТИНА": "TRUE", "ЛОЖЬ": "FALSE"}
@@ -0,0 +1,650 @@
+package excelformulaparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// The JSON encoding of an AST is described by ast.schema.json. Every node is
+// an object with a "type" discriminator (the Go type name, e.g. "CellExpr"),
+// its "start" and "end" positions, and its fields in camel case. Tokens are
+// objects with their type name, raw text, positions and trivia.
+
+var tokenTypeNames = [...]string{
+	Ident:                "Ident",
+	Number:               "Number",
+	String:               "String",
+	BoolLiteral:          "BoolLiteral",
+	EValue:               "EValue",
+	Exclamation:          "Exclamation",
+	BraceOpen:            "BraceOpen",
+	BraceClose:           "BraceClose",
+	BracketOpen:          "BracketOpen",
+	BracketClose:         "BracketClose",
+	ParenOpen:            "ParenOpen",
+	ParenClose:           "ParenClose",
+	Comma:                "Comma",
+	Semicolon:            "Semicolon",
+	ImplicitIntersection: "ImplicitIntersection",
+	Percent:              "Percent",
+	Exponentiation:       "Exponentiation",
+	Multiply:             "Multiply",
+	Divide:               "Divide",
+	Plus:                 "Plus",
+	Colon:                "Colon",
+	Minus:                "Minus",
+	Concat:               "Concat",
+	Equal:                "Equal",
+	NotEqual:             "NotEqual",
+	LessThan:             "LessThan",
+	GreaterThan:          "GreaterThan",
+	LessThanOrEqual:      "LessThanOrEqual",
+	GreaterThanOrEqual:   "GreaterThanOrEqual",
+	Cell:                 "Cell",
+	AbsoluteRow:          "AbsoluteRow",
+	AbsoluteColumn:       "AbsoluteColumn",
+	Bracketed:            "Bracketed",
+	Spill:                "Spill",
+	Intersection:         "Intersection",
+	R1C1Reference:        "R1C1Reference",
+	Backslash:            "Backslash",
+	Period:               "Period",
+	Whitespace:           "Whitespace",
+}
+
+type jsonPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p jsonPos) pos() Pos {
+	return Pos{Line: p.Line, Column: p.Column}
+}
+
+type jsonToken struct {
+	Type     string   `json:"type"`
+	Raw      string   `json:"raw"`
+	Start    jsonPos  `json:"start"`
+	End      jsonPos  `json:"end"`
+	Leading  []*Token `json:"leading,omitempty"`
+	Trailing []*Token `json:"trailing,omitempty"`
+}
+
+// MarshalJSON encodes the token with its type name, e.g.
+// {"type":"Number","raw":"1","start":{"line":1,"column":2},"end":{"line":1,"column":2}}.
+// Empty trivia is omitted.
+func (t *Token) MarshalJSON() ([]byte, error) {
+	if t.Type <= 0 || int(t.Type) >= len(tokenTypeNames) {
+		return nil, fmt.Errorf("invalid token type %d", t.Type)
+	}
+	return json.Marshal(jsonToken{
+		Type:     tokenTypeNames[t.Type],
+		Raw:      t.Raw,
+		Start:    jsonPos(t.Start),
+		End:      jsonPos(t.End),
+		Leading:  t.Leading,
+		Trailing: t.Trailing,
+	})
+}
+
+// UnmarshalJSON decodes a token encoded by MarshalJSON.
+func (t *Token) UnmarshalJSON(data []byte) error {
+	var w jsonToken
+	if err := json.Unmarshal(data, &w); err != nil {
+		return err
+	}
+	var typ TokenType
+	for i, name := range tokenTypeNames {
+		if name != "" && name == w.Type {
+			typ = TokenType(i)
+		}
+	}
+	if typ == 0 {
+		return fmt.Errorf("unknown token type %q", w.Type)
+	}
+	*t = Token{Start: w.Start.pos(), End: w.End.pos(), Type: typ, Raw: w.Raw, Leading: w.Leading, Trailing: w.Trailing}
+	return nil
+}
+
+type jsonHeader struct {
+	Type  string  `json:"type"`
+	Start jsonPos `json:"start"`
+	End   jsonPos `json:"end"`
+}
+
+func header(typ string, b baseNode) jsonHeader {
+	return jsonHeader{Type: typ, Start: jsonPos(b.start), End: jsonPos(b.end)}
+}
+
+func (h jsonHeader) base() baseNode {
+	return newBaseNode(h.Start.pos(), h.End.pos())
+}
+
+func (f FunCallExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Name       *Token   `json:"name"`
+		LocalName  *Token   `json:"localName"`
+		ParenOpen  *Token   `json:"parenOpen"`
+		Arguments  []Node   `json:"arguments"`
+		Separators []*Token `json:"separators"`
+		ParenClose *Token   `json:"parenClose"`
+	}{header("FunCallExpr", f.baseNode), f.Name, f.LocalName, f.ParanOpen, f.Arguments, f.Separators, f.ParanClose})
+}
+
+type jsonLetBinding struct {
+	Name  *Token `json:"name"`
+	Value Node   `json:"value"`
+}
+
+func (l LetExpr) MarshalJSON() ([]byte, error) {
+	var bindings []jsonLetBinding
+	if l.Bindings != nil {
+		bindings = make([]jsonLetBinding, len(l.Bindings))
+		for i, binding := range l.Bindings {
+			bindings[i] = jsonLetBinding(binding)
+		}
+	}
+	return json.Marshal(struct {
+		jsonHeader
+		Name       *Token           `json:"name"`
+		ParenOpen  *Token           `json:"parenOpen"`
+		Bindings   []jsonLetBinding `json:"bindings"`
+		Body       Node             `json:"body"`
+		Separators []*Token         `json:"separators"`
+		ParenClose *Token           `json:"parenClose"`
+	}{header("LetExpr", l.baseNode), l.Name, l.ParenOpen, bindings, l.Body, l.Separators, l.ParenClose})
+}
+
+func (l LambdaExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Name       *Token   `json:"name"`
+		ParenOpen  *Token   `json:"parenOpen"`
+		Params     []*Token `json:"params"`
+		Body       Node     `json:"body"`
+		Separators []*Token `json:"separators"`
+		ParenClose *Token   `json:"parenClose"`
+	}{header("LambdaExpr", l.baseNode), l.Name, l.ParenOpen, l.Params, l.Body, l.Separators, l.ParenClose})
+}
+
+func (c CallExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Callee     Node     `json:"callee"`
+		ParenOpen  *Token   `json:"parenOpen"`
+		Arguments  []Node   `json:"arguments"`
+		Separators []*Token `json:"separators"`
+		ParenClose *Token   `json:"parenClose"`
+	}{header("CallExpr", c.baseNode), c.Callee, c.ParenOpen, c.Arguments, c.Separators, c.ParenClose})
+}
+
+func (e EmptyArgExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(header("EmptyArgExpr", e.baseNode))
+}
+
+func (b BinaryExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Left     Node   `json:"left"`
+		Operator *Token `json:"operator"`
+		Right    Node   `json:"right"`
+	}{header("BinaryExpr", b.baseNode), b.Left, b.Operator, b.Right})
+}
+
+func (u UnaryExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Operator *Token `json:"operator"`
+		Operand  Node   `json:"operand"`
+	}{header("UnaryExpr", u.baseNode), u.Operator, u.Operand})
+}
+
+func (l LiteralExpr) MarshalJSON() ([]byte, error) {
+	var kind string
+	if l.ErrorKind != 0 {
+		info, ok := l.ErrorKind.Info()
+		if !ok {
+			return nil, fmt.Errorf("invalid error kind %d", l.ErrorKind)
+		}
+		kind = info.Literal
+	}
+	return json.Marshal(struct {
+		jsonHeader
+		Value      *Token  `json:"value"`
+		LocalValue *Token  `json:"localValue,omitempty"`
+		Number     float64 `json:"number"`
+		ErrorKind  string  `json:"errorKind,omitempty"`
+	}{header("LiteralExpr", l.baseNode), l.Value, l.LocalValue, l.Number, kind})
+}
+
+func (i IdentExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Name    *Token `json:"name"`
+		Binding *Token `json:"binding"`
+	}{header("IdentExpr", i.baseNode), i.Name, i.Binding})
+}
+
+func (p ParenthesizedExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		ParenOpen  *Token `json:"parenOpen"`
+		Inner      Node   `json:"inner"`
+		ParenClose *Token `json:"parenClose"`
+	}{header("ParenthesizedExpr", p.baseNode), p.ParenOpen, p.Inner, p.ParenClose})
+}
+
+func (r RangeExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Begin  Node     `json:"begin"`
+		Colons []*Token `json:"colons"`
+		Ends   []Node   `json:"ends"`
+	}{header("RangeExpr", r.baseNode), r.Begin, r.Colons, r.Ends})
+}
+
+func (s SheetRefExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Sheet       string `json:"sheet"`
+		SheetToken  *Token `json:"sheetToken"`
+		Exclamation *Token `json:"exclamation"`
+		Ref         Node   `json:"ref"`
+	}{header("SheetRefExpr", s.baseNode), s.Sheet, s.SheetToken, s.Exclamation, s.Ref})
+}
+
+func (s Sheet3DRefExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		FirstSheet      string `json:"firstSheet"`
+		LastSheet       string `json:"lastSheet"`
+		FirstSheetToken *Token `json:"firstSheetToken"`
+		Colon           *Token `json:"colon"`
+		LastSheetToken  *Token `json:"lastSheetToken"`
+		Exclamation     *Token `json:"exclamation"`
+		Ref             Node   `json:"ref"`
+	}{header("Sheet3DRefExpr", s.baseNode), s.FirstSheet, s.LastSheet, s.FirstSheetToken, s.Colon, s.LastSheetToken, s.Exclamation, s.Ref})
+}
+
+func (e ExternalRefExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Path        string   `json:"path"`
+		Workbook    string   `json:"workbook"`
+		Sheet       string   `json:"sheet"`
+		LastSheet   string   `json:"lastSheet"`
+		Prefix      []*Token `json:"prefix"`
+		Exclamation *Token   `json:"exclamation"`
+		Ref         Node     `json:"ref"`
+	}{header("ExternalRefExpr", e.baseNode), e.Path, e.Workbook, e.Sheet, e.LastSheet, e.Prefix, e.Exclamation, e.Ref})
+}
+
+func (s StructuredRefExpr) MarshalJSON() ([]byte, error) {
+	var items []string
+	if s.Items != nil {
+		items = make([]string, len(s.Items))
+		for i, item := range s.Items {
+			if item < ItemAll || item > ItemThisRow {
+				return nil, fmt.Errorf("invalid structured reference item %d", item)
+			}
+			items[i] = item.String()
+		}
+	}
+	return json.Marshal(struct {
+		jsonHeader
+		Table       *Token   `json:"table"`
+		Specifier   *Token   `json:"specifier"`
+		Items       []string `json:"items"`
+		FirstColumn string   `json:"firstColumn"`
+		LastColumn  string   `json:"lastColumn"`
+	}{header("StructuredRefExpr", s.baseNode), s.Table, s.Specifier, items, s.FirstColumn, s.LastColumn})
+}
+
+func (c CellExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Ident       *Token `json:"ident"`
+		Row         int    `json:"row"`
+		Col         int    `json:"col"`
+		RowAbsolute bool   `json:"rowAbsolute"`
+		ColAbsolute bool   `json:"colAbsolute"`
+	}{header("CellExpr", c.baseNode), c.Ident, c.Row, c.Col, c.RowAbsolute, c.ColAbsolute})
+}
+
+type jsonR1C1Index struct {
+	Value    int  `json:"value"`
+	Relative bool `json:"relative"`
+	Omitted  bool `json:"omitted"`
+}
+
+func (r R1C1Expr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		Ident *Token        `json:"ident"`
+		Row   jsonR1C1Index `json:"row"`
+		Col   jsonR1C1Index `json:"col"`
+	}{header("R1C1Expr", r.baseNode), r.Ident, jsonR1C1Index(r.Row), jsonR1C1Index(r.Col)})
+}
+
+func (a ArrayExpr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		jsonHeader
+		BraceOpen  *Token   `json:"braceOpen"`
+		Elements   [][]Node `json:"elements"`
+		Separators []*Token `json:"separators"`
+		BraceClose *Token   `json:"braceClose"`
+	}{header("ArrayExpr", a.baseNode), a.BraceOpen, a.Elements, a.Separators, a.BraceClose})
+}
+
+// UnmarshalNode decodes an AST encoded with json.Marshal. The result is equal
+// to the encoded tree, including positions, trivia and the declarations that
+// identifiers are bound to (IdentExpr.Binding). The JSON null decodes to a nil
+// Node.
+func UnmarshalNode(data []byte) (Node, error) {
+	var d = &decoder{}
+	var node = d.node(data)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return node, nil
+}
+
+type decoder struct {
+	err    error
+	scopes [][]*Token // the declarations of the enclosing LET and LAMBDA expressions, innermost last
+}
+
+// decode unmarshals data into v, unless decoding already failed.
+func (d *decoder) decode(data []byte, v any) bool {
+	if d.err != nil {
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		d.err = err
+		return false
+	}
+	return true
+}
+
+func (d *decoder) nodes(list []json.RawMessage) []Node {
+	if list == nil {
+		return nil
+	}
+	var nodes = make([]Node, len(list))
+	for i, data := range list {
+		nodes[i] = d.node(data)
+	}
+	return nodes
+}
+
+// binding returns the declaration in scope that t was encoded from.
+func (d *decoder) binding(t *Token) *Token {
+	if t == nil {
+		return nil
+	}
+	for i := len(d.scopes) - 1; i >= 0; i-- {
+		for _, decl := range d.scopes[i] {
+			if decl.Raw == t.Raw && decl.Start == t.Start && decl.End == t.End {
+				return decl
+			}
+		}
+	}
+	return t
+}
+
+func (d *decoder) node(data []byte) Node {
+	if data = bytes.TrimSpace(data); len(data) == 0 || string(data) == "null" {
+		return nil // a missing or null child
+	}
+	var h jsonHeader
+	if !d.decode(data, &h) {
+		return nil
+	}
+	var base = h.base()
+
+	switch h.Type {
+	case "FunCallExpr":
+		var w struct {
+			Name, LocalName, ParenOpen, ParenClose *Token
+			Arguments                              []json.RawMessage
+			Separators                             []*Token
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return FunCallExpr{baseNode: base, Name: w.Name, LocalName: w.LocalName, ParanOpen: w.ParenOpen, Arguments: d.nodes(w.Arguments), Separators: w.Separators, ParanClose: w.ParenClose}
+
+	case "LetExpr":
+		var w struct {
+			Name, ParenOpen, ParenClose *Token
+			Bindings                    []struct {
+				Name  *Token
+				Value json.RawMessage
+			}
+			Body       json.RawMessage
+			Separators []*Token
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		var n = LetExpr{baseNode: base, Name: w.Name, ParenOpen: w.ParenOpen, Separators: w.Separators, ParenClose: w.ParenClose}
+		var scope []*Token
+		for _, binding := range w.Bindings {
+			scope = append(scope, binding.Name)
+		}
+		d.scopes = append(d.scopes, scope)
+		if w.Bindings != nil {
+			n.Bindings = make([]LetBinding, len(w.Bindings))
+			for i, binding := range w.Bindings {
+				n.Bindings[i] = LetBinding{Name: binding.Name, Value: d.node(binding.Value)}
+			}
+		}
+		n.Body = d.node(w.Body)
+		d.scopes = d.scopes[:len(d.scopes)-1]
+		return n
+
+	case "LambdaExpr":
+		var w struct {
+			Name, ParenOpen, ParenClose *Token
+			Params, Separators          []*Token
+			Body                        json.RawMessage
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		d.scopes = append(d.scopes, w.Params)
+		var body = d.node(w.Body)
+		d.scopes = d.scopes[:len(d.scopes)-1]
+		return LambdaExpr{baseNode: base, Name: w.Name, ParenOpen: w.ParenOpen, Params: w.Params, Body: body, Separators: w.Separators, ParenClose: w.ParenClose}
+
+	case "CallExpr":
+		var w struct {
+			Callee                json.RawMessage
+			ParenOpen, ParenClose *Token
+			Arguments             []json.RawMessage
+			Separators            []*Token
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return CallExpr{baseNode: base, Callee: d.node(w.Callee), ParenOpen: w.ParenOpen, Arguments: d.nodes(w.Arguments), Separators: w.Separators, ParenClose: w.ParenClose}
+
+	case "EmptyArgExpr":
+		return EmptyArgExpr{baseNode: base}
+
+	case "BinaryExpr":
+		var w struct {
+			Left, Right json.RawMessage
+			Operator    *Token
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return BinaryExpr{baseNode: base, Left: d.node(w.Left), Operator: w.Operator, Right: d.node(w.Right)}
+
+	case "UnaryExpr":
+		var w struct {
+			Operator *Token
+			Operand  json.RawMessage
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return UnaryExpr{baseNode: base, Operator: w.Operator, Operand: d.node(w.Operand)}
+
+	case "LiteralExpr":
+		var w struct {
+			Value, LocalValue *Token
+			Number            float64
+			ErrorKind         string
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		var n = LiteralExpr{baseNode: base, Value: w.Value, LocalValue: w.LocalValue, Number: w.Number}
+		if w.ErrorKind != "" {
+			info, ok := LookupErrorValue(w.ErrorKind)
+			if !ok {
+				d.err = fmt.Errorf("unknown error value %q", w.ErrorKind)
+				return nil
+			}
+			n.ErrorKind = info.Kind
+		}
+		return n
+
+	case "IdentExpr":
+		var w struct {
+			Name, Binding *Token
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return IdentExpr{baseNode: base, Name: w.Name, Binding: d.binding(w.Binding)}
+
+	case "ParenthesizedExpr":
+		var w struct {
+			ParenOpen, ParenClose *Token
+			Inner                 json.RawMessage
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return ParenthesizedExpr{baseNode: base, ParenOpen: w.ParenOpen, Inner: d.node(w.Inner), ParenClose: w.ParenClose}
+
+	case "RangeExpr":
+		var w struct {
+			Begin  json.RawMessage
+			Colons []*Token
+			Ends   []json.RawMessage
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return RangeExpr{baseNode: base, Begin: d.node(w.Begin), Colons: w.Colons, Ends: d.nodes(w.Ends)}
+
+	case "SheetRefExpr":
+		var w struct {
+			Sheet                   string
+			SheetToken, Exclamation *Token
+			Ref                     json.RawMessage
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return SheetRefExpr{baseNode: base, Sheet: w.Sheet, SheetToken: w.SheetToken, Exclamation: w.Exclamation, Ref: d.node(w.Ref)}
+
+	case "Sheet3DRefExpr":
+		var w struct {
+			FirstSheet, LastSheet                               string
+			FirstSheetToken, Colon, LastSheetToken, Exclamation *Token
+			Ref                                                 json.RawMessage
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return Sheet3DRefExpr{baseNode: base, FirstSheet: w.FirstSheet, LastSheet: w.LastSheet, FirstSheetToken: w.FirstSheetToken, Colon: w.Colon, LastSheetToken: w.LastSheetToken, Exclamation: w.Exclamation, Ref: d.node(w.Ref)}
+
+	case "ExternalRefExpr":
+		var w struct {
+			Path, Workbook, Sheet, LastSheet string
+			Prefix                           []*Token
+			Exclamation                      *Token
+			Ref                              json.RawMessage
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return ExternalRefExpr{baseNode: base, Path: w.Path, Workbook: w.Workbook, Sheet: w.Sheet, LastSheet: w.LastSheet, Prefix: w.Prefix, Exclamation: w.Exclamation, Ref: d.node(w.Ref)}
+
+	case "StructuredRefExpr":
+		var w struct {
+			Table, Specifier        *Token
+			Items                   []string
+			FirstColumn, LastColumn string
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		var n = StructuredRefExpr{baseNode: base, Table: w.Table, Specifier: w.Specifier, FirstColumn: w.FirstColumn, LastColumn: w.LastColumn}
+		if w.Items != nil {
+			n.Items = make([]StructuredItem, len(w.Items))
+		}
+		for i, name := range w.Items {
+			for item := ItemAll; item <= ItemThisRow; item++ {
+				if item.String() == name {
+					n.Items[i] = item
+				}
+			}
+			if n.Items[i] == 0 {
+				d.err = fmt.Errorf("unknown structured reference item %q", name)
+				return nil
+			}
+		}
+		return n
+
+	case "CellExpr":
+		var w struct {
+			Ident                    *Token
+			Row, Col                 int
+			RowAbsolute, ColAbsolute bool
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return CellExpr{baseNode: base, Ident: w.Ident, Row: w.Row, Col: w.Col, RowAbsolute: w.RowAbsolute, ColAbsolute: w.ColAbsolute}
+
+	case "R1C1Expr":
+		var w struct {
+			Ident    *Token
+			Row, Col jsonR1C1Index
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		return R1C1Expr{baseNode: base, Ident: w.Ident, Row: R1C1Index(w.Row), Col: R1C1Index(w.Col)}
+
+	case "ArrayExpr":
+		var w struct {
+			BraceOpen, BraceClose *Token
+			Elements              [][]json.RawMessage
+			Separators            []*Token
+		}
+		if !d.decode(data, &w) {
+			return nil
+		}
+		var n = ArrayExpr{baseNode: base, BraceOpen: w.BraceOpen, Separators: w.Separators, BraceClose: w.BraceClose}
+		if w.Elements != nil {
+			n.Elements = make([][]Node, len(w.Elements))
+			for i, row := range w.Elements {
+				n.Elements[i] = d.nodes(row)
+			}
+		}
+		return n
+
+	default:
+		d.err = fmt.Errorf("unknown node type %q", h.Type)
+		return nil
+	}
+}
@@ -0,0 +1,170 @@
+package excelformulaparser
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		options ParseOptions
+		src     string
+	}{
+		{ParseOptions{}, "=A1+B1*2"},
+		{ParseOptions{}, " =  SUM( A1 ,\n\tB1 )  \n"},
+		{ParseOptions{}, "=-2 + -A1% & \"x\"\"y\""},
+		{ParseOptions{}, "=IF(A1,,#DIV/0!)"},
+		{ParseOptions{}, "=SUM()"},
+		{ParseOptions{}, "=(A1:C5 B2:D8, $A$1:A:A)"},
+		{ParseOptions{}, "={1,2;3,TRUE}"},
+		{ParseOptions{}, "=LET(x, 1, y, x * 2, LAMBDA(a, a + x + y)(3))"},
+		{ParseOptions{}, "=SUM(Jan:Dec!B2, 'Jan 2024:Dec 2024'!C4, 'My Sheet'!A1, [1]Sheet1!A1)"},
+		{ParseOptions{}, "=Table1[[#Headers],[Col A]:[Col C]] + [@Col] + @A1:B2 + A2#"},
+		{ParseOptions{R1C1: true}, "=SUM(R1C1:R[-1]C, RC[2])"},
+		{ParseOptions{Locale: LocaleDE}, "=SUMME(A1;1,5;{1.2};{WAHR.FALSCH})"},
+	}
+	for _, test := range tests {
+		node, err := NewParserWithOptions(test.src, test.options).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		data, err := json.Marshal(node)
+		if err != nil {
+			t.Errorf("For input '%s', marshal error: %v", test.src, err)
+			continue
+		}
+		result, err := UnmarshalNode(data)
+		if err != nil {
+			t.Errorf("For input '%s', unmarshal error: %v", test.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, node) {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, node, result)
+		}
+		if got := Source(result); got != test.src {
+			t.Errorf("For input '%s', expected the source '%s', got '%s'", test.src, test.src, got)
+		}
+		again, err := json.Marshal(result)
+		if err != nil || string(again) != string(data) {
+			t.Errorf("For input '%s', expected '%s', got '%s' (%v)", test.src, data, again, err)
+		}
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	node, err := NewParser("=-TRUE").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	data, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var expected = `{"type":"UnaryExpr","start":{"line":1,"column":2},"end":{"line":1,"column":6},` +
+		`"operator":{"type":"Minus","raw":"-","start":{"line":1,"column":2},"end":{"line":1,"column":2},` +
+		`"leading":[{"type":"Equal","raw":"=","start":{"line":1,"column":1},"end":{"line":1,"column":1}}]},` +
+		`"operand":{"type":"LiteralExpr","start":{"line":1,"column":3},"end":{"line":1,"column":6},` +
+		`"value":{"type":"BoolLiteral","raw":"TRUE","start":{"line":1,"column":3},"end":{"line":1,"column":6}},"number":0}}`
+	if string(data) != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, data)
+	}
+}
+
+func TestJSONBinding(t *testing.T) {
+	node, err := NewParser("=LET(x, 1, LAMBDA(x, x)(x))").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	data, err := json.Marshal(node)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	result, err := UnmarshalNode(data)
+	if err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	var let = result.(LetExpr)
+	var call = let.Body.(CallExpr)
+	var lambda = call.Callee.(LambdaExpr)
+	if lambda.Body.(IdentExpr).Binding != lambda.Params[0] {
+		t.Errorf("Expected the LAMBDA body to refer to the parameter")
+	}
+	if call.Arguments[0].(IdentExpr).Binding != let.Bindings[0].Name {
+		t.Errorf("Expected the argument to refer to the LET name")
+	}
+}
+
+func TestUnmarshalNodeError(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{`{"type":"FooExpr"}`, `unknown node type "FooExpr"`},
+		{`{"type":"CellExpr","ident":{"type":"Foo","raw":"A1"}}`, `unknown token type "Foo"`},
+		{`{"type":"LiteralExpr","errorKind":"#FOO!"}`, `unknown error value "#FOO!"`},
+		{`{"type":"StructuredRefExpr","items":["#Foo"]}`, `unknown structured reference item "#Foo"`},
+		{`{"type":"BinaryExpr","left":{"type":"CellExpr","row":"1"}}`, `cannot unmarshal string`},
+		{`[1]`, `cannot unmarshal array`},
+	}
+	for _, test := range tests {
+		_, err := UnmarshalNode([]byte(test.data))
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("For input '%s', expected error '%s', got '%v'", test.data, test.expected, err)
+		}
+	}
+
+	node, err := UnmarshalNode([]byte("null"))
+	if node != nil || err != nil {
+		t.Errorf("Expected a nil node, got %v, %v", node, err)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	data, err := os.ReadFile("ast.schema.json")
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	var schema struct {
+		Defs map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Invalid schema: %v", err)
+	}
+	// every node type and each of its properties is described by the schema
+	node, err := NewParser("=LET(x, {1,2}, LAMBDA(a, a)(x) + SUM(Jan:Dec!A1:B2, 'S'!C1, [1]S!D1, T[@C], -(x%), IF(,)))").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	r1c1, err := NewParserWithOptions("=R1C1", ParseOptions{R1C1: true}).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var check = func(n Node) bool {
+		if n == nil {
+			return false
+		}
+		var fields map[string]json.RawMessage
+		data, _ := json.Marshal(n)
+		json.Unmarshal(data, &fields)
+		var typ = strings.Trim(string(fields["type"]), `"`)
+		def, ok := schema.Defs[typ]
+		if !ok {
+			t.Errorf("Expected a schema definition for %s", typ)
+			return true
+		}
+		for name := range fields {
+			if _, ok := def.Properties[name]; !ok {
+				t.Errorf("Expected the schema definition of %s to describe '%s'", typ, name)
+			}
+		}
+		return true
+	}
+	Inspect(node, check)
+	Inspect(r1c1, check)
+}
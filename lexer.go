@@ -2,6 +2,8 @@ package excelformulaparser
 
 import (
 	"io"
+	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -19,29 +21,76 @@ type tokenState struct {
 }
 
 type lexer struct {
-	src    []rune
-	pos    Pos
-	offset int
-	ch     rune
+	src         []rune
+	pos         Pos
+	offset      int
+	ch          rune
+	prev        *Token            // The previously scanned token
+	r1c1        bool              // Lex references in R1C1 notation instead of A1 notation
+	decimal     rune              // The decimal separator of number literals
+	booleans    map[string]string // The localized TRUE and FALSE in upper case, lexed as BoolLiteral (e.g., WAHR)
+	arrayColumn rune              // The column separator of array constants, which ends a name inside them
+	arrays      int               // The number of array constants open at the current character
 	tokenState
 }
 
 func newLexer(src string) *lexer {
 	var l = &lexer{
-		src: []rune(src),
+		src:     []rune(src),
+		decimal: '.',
 	}
 	l.init()
 	return l
 }
 
 func (l *lexer) next() (*Token, error) {
+	var token, err = l.scan()
+	if token != nil {
+		l.prev = token
+	}
+	return token, err
+}
+
+func (l *lexer) scan() (*Token, error) {
 	if len(l.src) == 0 || l.ch == -1 {
 		return nil, io.EOF // EOF
 	}
-	for l.ch == ' ' || l.ch == '\n' || l.ch == '\t' {
+	var space = l.whitespace()
+	if l.ch == -1 {
+		if space != nil && l.prev != nil {
+			l.prev.Trailing = append(l.prev.Trailing, space)
+		}
+		return nil, io.EOF // EOF after trailing whitespace
+	}
+	var token, err = l.token()
+	if token != nil && space != nil {
+		token.Leading = []*Token{space}
+	}
+	return token, err
+}
+
+// whitespace consumes the whitespace before a token. It returns nil if there is none.
+func (l *lexer) whitespace() *Token {
+	if !isWhitespace(l.ch) {
+		return nil
+	}
+	var startOffset = l.offset - 1 // Start at the current character
+	var start = l.pos
+	var end = l.pos
+	var endOffset = l.offset
+	for isWhitespace(l.ch) {
+		end = l.pos
+		endOffset = l.offset
 		l.nextch()
 	}
+	return newToken(start, end, Whitespace, string(l.src[startOffset:endOffset]))
+}
+
+func (l *lexer) token() (*Token, error) {
 	var start = l.pos
+	if l.ch == l.decimal && isDigit(l.peekChar()) {
+		return l.number() // e.g., .5, or ,5 with a decimal comma
+	}
 	switch l.ch {
 	case '"':
 		return l.stringLiteral(l.ch)
@@ -64,9 +113,13 @@ func (l *lexer) next() (*Token, error) {
 		return newToken(start, start, ParenClose, ")"), nil
 	case '{':
 		l.nextch()
+		l.arrays++
 		return newToken(start, start, BraceOpen, "{"), nil
 	case '}':
 		l.nextch()
+		if l.arrays > 0 {
+			l.arrays--
+		}
 		return newToken(start, start, BraceClose, "}"), nil
 	case '[':
 		l.nextch()
@@ -127,10 +180,20 @@ func (l *lexer) next() (*Token, error) {
 			return newToken(start, l.pos, GreaterThan, ">"), nil
 		}
 	case '#':
+		if l.prev != nil && isSpillable(l.prev.Type) && l.prev.End.Line == start.Line && l.prev.End.Column+1 == start.Column {
+			l.nextch()
+			return newToken(start, start, Spill, "#"), nil // e.g., A1#
+		}
 		return l.errValue()
 	case '@':
 		l.nextch()
 		return newToken(start, start, ImplicitIntersection, "@"), nil
+	case '\\':
+		l.nextch()
+		return newToken(start, start, Backslash, "\\"), nil
+	case '.':
+		l.nextch()
+		return newToken(start, start, Period, "."), nil
 	default:
 		if isDigit(l.ch) {
 			return l.number()
@@ -226,34 +289,57 @@ func (l *lexer) absoluteReference() (*Token, error) {
 	return nil, newLexError(l.pos, "invalid absolute reference after '$'")
 }
 
+// errValue scans an error value registered with RegisterErrorValue (e.g., #DIV/0!, #SPILL!).
 func (l *lexer) errValue() (*Token, error) {
-	var values = []string{
-		"#NULL!",
-		"#DIV/0!",
-		"#VALUE!",
-		"#REF!",
-		"#NAME?",
-		"#NUM!",
-		"#N/A",
-	}
-	for _, value := range values {
-		_len := utf8.RuneCountInString(value)
-		// check length
-		if l.offset-1+_len > len(l.src) {
-			continue // Not enough characters left for this error value
+	var _, length, ok = matchErrorValue(l.src[l.offset-1:])
+	if !ok {
+		return nil, newLexError(l.pos, "unrecognized error value")
+	}
+	var startOffset = l.offset - 1 // Start at the current character
+	var start = l.pos
+	var end = l.pos
+	for i := 0; i < length; i++ { // Consume the error value
+		end = l.pos
+		l.nextch()
+	}
+	return newToken(start, end, EValue, string(l.src[startOffset:startOffset+length])), nil
+}
+
+// bracketed scans the text following an already consumed '[' token up to the
+// matching ']'. Nested brackets are balanced and the character after an apostrophe
+// is taken literally, as in structured references (e.g., [[#Headers],[a'[b]]).
+// The returned token covers both brackets.
+func (l *lexer) bracketed(open *Token) (*Token, error) {
+	var startOffset = l.offset - 1 // Start at the current character
+	var depth = 1
+	for l.ch >= 0 {
+		switch l.ch {
+		case '\'':
+			l.nextch() // Consume the escape character
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				var end = l.pos
+				var raw = "[" + string(l.src[startOffset:l.offset-1]) + "]"
+				l.nextch() // Consume the closing bracket
+				var token = newToken(open.Start, end, Bracketed, raw)
+				token.Leading = open.Leading
+				l.prev = token
+				return token, nil
+			}
 		}
-		if string(l.src[l.offset-1:l.offset-1+_len]) == value {
-			var start = l.pos
-			l.eat(_len) // Consume the error value
-			var end = l.pos
-			return newToken(start, end, EValue, value), nil
+		if l.ch >= 0 {
+			l.nextch()
 		}
 	}
-	return nil, newLexError(l.pos, "unrecognized error value")
+	return nil, newLexError(l.pos, "unclosed '['")
 }
 
 func (l *lexer) stringLiteral(quote rune) (*Token, error) {
 	var start = l.pos
+	var startOffset = l.offset - 1 // Start at the current character
 	var end = start
 	l.nextch() // Consume the opening quote
 	for l.ch >= 0 {
@@ -274,38 +360,46 @@ func (l *lexer) stringLiteral(quote rune) (*Token, error) {
 		return nil, newLexError(l.pos, "unclosed string literal")
 	}
 	end = l.pos // Update end to the position after the closing quote
-	l.nextch()  // Consume the closing quote
-	return newToken(start, end, String, string(l.src[start.Column-1:end.Column])), nil
+	var endOffset = l.offset
+	l.nextch() // Consume the closing quote
+	return newToken(start, end, String, string(l.src[startOffset:endOffset])), nil
 }
 
+// number scans a number literal, including the scientific notation (e.g., 1.5E+10, 2e-3)
+// and numbers with a leading decimal separator (e.g., .5).
 func (l *lexer) number() (*Token, error) {
 	var startOffset = l.offset - 1 // Start at the current character
 	var endOffset = l.offset - 1
 	var start = l.pos
 	var end = l.pos
-	if !isDigit(l.ch) {
+	if !isDigit(l.ch) && !(l.ch == l.decimal && isDigit(l.peekChar())) {
 		return nil, newLexError(l.pos, "invalid number start")
 	}
-LOOP:
-	for l.ch >= 0 {
-		switch {
-		case isDigit(l.ch):
+	var digits = func() (n int) {
+		for isDigit(l.ch) {
 			end = l.pos
 			endOffset = l.offset
 			l.nextch()
-		case l.ch == '.':
-			l.nextch()
-			if !isDigit(l.ch) {
-				return nil, newLexError(l.pos, "invalid number format")
-			}
-			// Continue to consume digits after the decimal point
-			for isDigit(l.ch) {
-				end = l.pos
-				endOffset = l.offset
-				l.nextch()
-			}
-		default:
-			break LOOP
+			n++
+		}
+		return
+	}
+	digits()
+	if l.ch == l.decimal {
+		l.nextch()
+		// Continue to consume digits after the decimal point
+		if digits() == 0 {
+			return nil, newLexError(l.pos, "invalid number format")
+		}
+	}
+	if l.ch == 'e' || l.ch == 'E' {
+		var n = 1 // length of the exponent marker, including its sign
+		if sign := l.peekChar(); sign == '+' || sign == '-' {
+			n = 2
+		}
+		if l.offset-1+n < len(l.src) && isDigit(l.src[l.offset-1+n]) {
+			l.eat(n)
+			digits()
 		}
 	}
 	return newToken(start, end, Number, string(l.src[startOffset:endOffset])), nil
@@ -318,11 +412,18 @@ func (l *lexer) ident() (*Token, error) {
 	if !isASCIILetter(l.ch) && l.ch != '_' && l.ch <= utf8.RuneSelf {
 		return nil, newLexError(l.pos, "invalid identifier start")
 	}
+	if l.r1c1 {
+		if token := l.r1c1Reference(); token != nil {
+			return token, nil
+		}
+	}
 	var endOffset = l.offset
 	l.nextch()
 LOOP:
 	for l.ch >= 0 {
 		switch {
+		case l.ch == l.arrayColumn && l.arrays > 0:
+			break LOOP // e.g., the '.' in the German {WAHR.FALSCH}
 		case isASCIILetter(l.ch) || isDigit(l.ch) || l.ch == '.':
 			end = l.pos
 			endOffset = l.offset
@@ -343,7 +444,13 @@ LOOP:
 	if t, ok := keywords[rawIdent]; ok {
 		return newToken(start, end, t, rawIdent), nil
 	}
-	if isCellReference([]rune(rawIdent)) {
+	if _, ok := l.booleans[strings.ToUpper(rawIdent)]; ok {
+		return newToken(start, end, BoolLiteral, rawIdent), nil // translated by the parser
+	}
+	if l.r1c1 {
+		return newToken(start, end, Ident, rawIdent), nil // A1 style references are plain names in R1C1 mode
+	}
+	if isCellReference([]rune(rawIdent)) && l.ch != '(' { // e.g., LOG10( is a function name, not a cell
 		return newToken(start, end, Cell, rawIdent), nil
 	}
 	if l.ch == '$' {
@@ -359,6 +466,51 @@ LOOP:
 	return newToken(start, end, Ident, rawIdent), nil
 }
 
+// r1c1Reference scans an R1C1 style reference (e.g., R1C1, R[-1]C[2], RC, R2, C[3]).
+// It returns nil without consuming anything if the input does not start with one.
+func (l *lexer) r1c1Reference() *Token {
+	var src = l.src[l.offset-1:]
+	var n = 0
+	var axis = func(letter rune) {
+		if n >= len(src) || unicode.ToUpper(src[n]) != letter {
+			return
+		}
+		n++ // consume the letter
+		if n < len(src) && src[n] == '[' {
+			var i = n + 1
+			if i < len(src) && src[i] == '-' {
+				i++
+			}
+			var digits = i
+			for i < len(src) && isDigit(src[i]) {
+				i++
+			}
+			if i > digits && i < len(src) && src[i] == ']' {
+				n = i + 1 // consume the offset, e.g., [-1]
+			}
+			return
+		}
+		for n < len(src) && isDigit(src[n]) {
+			n++
+		}
+	}
+	axis('R')
+	axis('C')
+	if n == 0 {
+		return nil
+	}
+	if n < len(src) && (isIdentPart(src[n]) || src[n] == '[') {
+		return nil // e.g., ROUND, COUNT, R[x]
+	}
+	var start = l.pos
+	var end = l.pos
+	for i := 0; i < n; i++ {
+		end = l.pos
+		l.nextch()
+	}
+	return newToken(start, end, R1C1Reference, string(src[:n]))
+}
+
 func (l *lexer) eatDigits() (len int) {
 	var startOffset = l.offset - 1 // Start at the current character
 	var endOffset = l.offset - 1
@@ -378,6 +530,20 @@ func (l *lexer) peekChar() rune {
 	return -1 // EOF
 }
 
+// isSpillable reports whether a '#' directly after a token of type t is the
+// spilled range operator rather than the start of an error value.
+func isSpillable(t TokenType) bool {
+	return t == Cell || t == Ident || t == R1C1Reference
+}
+
+func isIdentPart(ch rune) bool {
+	return isASCIILetter(ch) || isDigit(ch) || ch == '.' || ch == '_' || ch > utf8.RuneSelf
+}
+
+func isWhitespace(ch rune) bool {
+	return ch == ' ' || ch == '\n' || ch == '\t' || ch == '\r'
+}
+
 func isASCIILetter(ch rune) bool {
 	return ch >= 'A' && ch <= 'Z' || ch >= 'a' && ch <= 'z'
 }
@@ -403,10 +569,13 @@ func isCellReference(raw []rune) bool {
 		return false // Must have at least one letter and at least one digit
 	}
 	// Check if the rest are digits (0-9)
+	var row = 0
 	for _, ch := range raw[letterCount:] {
 		if !isDigit(ch) {
 			return false
 		}
+		row = min(row*10+int(ch-'0'), MaxRows+1)
 	}
-	return true
+	// Columns after XFD and rows after 1048576 are names (e.g., ATAN2)
+	return letterCount <= 3 && colNameToIndex(string(raw[:letterCount])) < MaxColumns && row >= 1 && row <= MaxRows
 }
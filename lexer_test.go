@@ -16,10 +16,27 @@ func TestLexer(t *testing.T) {
 		{"#DIV/0!", EValue},
 		{"#VALUE!", EValue},
 		{"#N/A", EValue},
+		{"#NULL!", EValue},
+		{"#NAME?", EValue},
+		{"#SPILL!", EValue},
+		{"#CALC!", EValue},
+		{"#GETTING_DATA", EValue},
+		{"#FIELD!", EValue},
+		{"#BLOCKED!", EValue},
+		{"#BUSY!", EValue},
+		{"#CONNECT!", EValue},
+		{"#UNKNOWN!", EValue},
+		{"#PYTHON!", EValue},
+		{"#n/a", EValue},
 		{"A$123", Cell},
 		{"$A$1", Cell},
 		{"A1", Cell},
 		{"AA12", Cell},
+		{"XFD1048576", Cell},
+		{"XFE1", Ident},
+		{"A1048577", Ident},
+		{"A0", Ident},
+		{"ATAN2", Ident},
 		{"$ABC", AbsoluteColumn},
 		{"$123", AbsoluteRow},
 		{"TRUE", BoolLiteral},
@@ -28,6 +45,11 @@ func TestLexer(t *testing.T) {
 		{"1.23", Number},
 		{"0.23", Number},
 		{"0.230000", Number},
+		{"1.5E+10", Number},
+		{"2e-3", Number},
+		{"1E5", Number},
+		{".5", Number},
+		{".25e2", Number},
 		{`"hello"`, String},
 		{`'world'`, String},
 		{"=", Equal},
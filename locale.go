@@ -0,0 +1,110 @@
+package excelformulaparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locale describes a localized formula syntax, such as =SUMME(A1;B1) with a
+// decimal comma in German Excel.
+type Locale struct {
+	ArgSeparator         rune              // Separates function arguments and union operands (e.g., ',' or ';')
+	ArrayRowSeparator    rune              // Separates the rows of array constants (e.g., ';')
+	ArrayColumnSeparator rune              // Separates the columns of array constants (e.g., ',', '\' or '.')
+	DecimalSeparator     rune              // The decimal separator of numbers (e.g., '.' or ',')
+	Functions            map[string]string // Localized function names in upper case mapped to English names (e.g., "SUMME" to "SUM")
+	Booleans             map[string]string // Localized TRUE and FALSE in upper case mapped to English (e.g., "WAHR" to "TRUE")
+}
+
+// DefaultLocale is the en-US syntax also used in OOXML files, e.g. =SUM(1.5,{1,2;3,4}).
+var DefaultLocale = &Locale{
+	ArgSeparator:         ',',
+	ArrayRowSeparator:    ';',
+	ArrayColumnSeparator: ',',
+	DecimalSeparator:     '.',
+}
+
+// Presets for the languages with built-in function name tables.
+var (
+	LocaleDE = &Locale{';', ';', '.', ',', functionNamesDE, booleanNamesDE}
+	LocaleFR = &Locale{';', ';', '\\', ',', functionNamesFR, booleanNamesFR}
+	LocaleES = &Locale{';', ';', '\\', ',', functionNamesES, booleanNamesES}
+	LocaleIT = &Locale{';', ';', '\\', ',', functionNamesIT, booleanNamesIT}
+	LocaleNL = &Locale{';', ';', '\\', ',', functionNamesNL, booleanNamesNL}
+	LocalePT = &Locale{';', ';', '\\', ',', functionNamesPT, booleanNamesPT}
+	LocaleRU = &Locale{';', ';', '\\', ',', functionNamesRU, booleanNamesRU}
+)
+
+var separatorTypes = map[rune]TokenType{
+	',':  Comma,
+	';':  Semicolon,
+	'\\': Backslash,
+	'.':  Period,
+}
+
+// Validate reports whether the separators can be told apart by the parser.
+func (l *Locale) Validate() error {
+	for _, sep := range []rune{l.ArgSeparator, l.ArrayRowSeparator, l.ArrayColumnSeparator} {
+		if _, ok := separatorTypes[sep]; !ok {
+			return fmt.Errorf("unsupported separator %q", sep)
+		}
+	}
+	if l.DecimalSeparator != '.' && l.DecimalSeparator != ',' {
+		return fmt.Errorf("unsupported decimal separator %q", l.DecimalSeparator)
+	}
+	if l.ArrayRowSeparator == l.ArrayColumnSeparator {
+		return fmt.Errorf("array row and column separators must differ, both are %q", l.ArrayRowSeparator)
+	}
+	if l.DecimalSeparator == l.ArgSeparator || l.DecimalSeparator == l.ArrayColumnSeparator || l.DecimalSeparator == l.ArrayRowSeparator {
+		return fmt.Errorf("decimal separator %q conflicts with a list separator", l.DecimalSeparator)
+	}
+	return nil
+}
+
+type separators struct {
+	argument    TokenType
+	arrayRow    TokenType
+	arrayColumn TokenType
+}
+
+func (l *Locale) separators() separators {
+	return separators{
+		argument:    separatorTypes[l.ArgSeparator],
+		arrayRow:    separatorTypes[l.ArrayRowSeparator],
+		arrayColumn: separatorTypes[l.ArrayColumnSeparator],
+	}
+}
+
+// canonicalName returns the English name of a localized function name.
+func (l *Locale) canonicalName(name string) (string, bool) {
+	canonical, ok := l.Functions[strings.ToUpper(name)]
+	return canonical, ok
+}
+
+// canonicalBoolean returns TRUE or FALSE for a localized boolean.
+func (l *Locale) canonicalBoolean(name string) (string, bool) {
+	canonical, ok := l.Booleans[strings.ToUpper(name)]
+	return canonical, ok
+}
+
+// localNames returns the reverse of Functions, from English to localized names.
+// Aliases of the same function resolve to the alphabetically first one.
+func (l *Locale) localNames() map[string]string {
+	return reverseNames(l.Functions)
+}
+
+// localBooleans returns the reverse of Booleans, from TRUE and FALSE to localized names.
+func (l *Locale) localBooleans() map[string]string {
+	return reverseNames(l.Booleans)
+}
+
+// reverseNames maps the English names of m to their alphabetically first localized name.
+func reverseNames(m map[string]string) map[string]string {
+	var names = make(map[string]string, len(m))
+	for local, canonical := range m {
+		if prev, ok := names[canonical]; !ok || local < prev {
+			names[canonical] = local
+		}
+	}
+	return names
+}
@@ -0,0 +1,152 @@
+package excelformulaparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLocale(t *testing.T) {
+	var semicolon = &Locale{
+		ArgSeparator:         ';',
+		ArrayRowSeparator:    ';',
+		ArrayColumnSeparator: '.',
+		DecimalSeparator:     ',',
+	}
+	var backslash = &Locale{
+		ArgSeparator:         ';',
+		ArrayRowSeparator:    ';',
+		ArrayColumnSeparator: '\\',
+		DecimalSeparator:     ',',
+	}
+	tests := []struct {
+		locale   *Locale
+		src      string
+		expected string
+	}{
+		{semicolon, "=SUMME(A1;B1)", "FunCallExpr(Name: SUMME, Arguments: [CellExpr(A1), CellExpr(B1)])"},
+		{semicolon, "=1,5*2", "BinaryExpr(Left: LiteralExpr(Value: 1,5), Operator: *, Right: LiteralExpr(Value: 2))"},
+		{semicolon, "=WENN(A1;;-,5)", "FunCallExpr(Name: WENN, Arguments: [CellExpr(A1), EmptyArgExpr(), LiteralExpr(Value: -,5)])"},
+		{semicolon, "={1.2;3,5.4}", "ArrayExpr([LiteralExpr(Value: 1), LiteralExpr(Value: 2)], [LiteralExpr(Value: 3,5), LiteralExpr(Value: 4)])"},
+		{semicolon, "=SUMME((A1;B1);2)", "FunCallExpr(Name: SUMME, Arguments: [ParenthesizedExpr(Inner: BinaryExpr(Left: CellExpr(A1), Operator: ;, Right: CellExpr(B1))), LiteralExpr(Value: 2)])"},
+		{semicolon, "=LET(x;1;x)", "LetExpr(Bindings: [x = LiteralExpr(Value: 1)], Body: IdentExpr(Name: x))"},
+		{backslash, `={1\2;3\4}`, "ArrayExpr([LiteralExpr(Value: 1), LiteralExpr(Value: 2)], [LiteralExpr(Value: 3), LiteralExpr(Value: 4)])"},
+		{nil, "={1,2;3,4}", "ArrayExpr([LiteralExpr(Value: 1), LiteralExpr(Value: 2)], [LiteralExpr(Value: 3), LiteralExpr(Value: 4)])"},
+	}
+	for _, test := range tests {
+		node, err := NewParserWithOptions(test.src, ParseOptions{Locale: test.locale}).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		if node.String() != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, node.String())
+		}
+	}
+
+	node, err := NewParserWithOptions("=1,5E+2", ParseOptions{Locale: semicolon}).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if lit := node.(LiteralExpr); lit.Number != 150 {
+		t.Errorf("Expected 150, got %v", lit.Number)
+	}
+	if _, err := NewParserWithOptions("=SUMME(A1,B1)", ParseOptions{Locale: semicolon}).Parse(); err == nil {
+		t.Errorf("Expected parse error for ',' as argument separator")
+	}
+}
+
+func TestLocaleValidate(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		valid  bool
+	}{
+		{*DefaultLocale, true},
+		{Locale{';', ';', '\\', ',', nil, nil}, true},
+		{Locale{'|', ';', ',', '.', nil, nil}, false},
+		{Locale{',', ';', ';', '.', nil, nil}, false},
+		{Locale{',', ';', '.', '.', nil, nil}, false},
+		{Locale{',', ';', '\\', ',', nil, nil}, false},
+		{Locale{';', ';', '\\', '_', nil, nil}, false},
+	}
+	for _, test := range tests {
+		var err = test.locale.Validate()
+		if (err == nil) != test.valid {
+			t.Errorf("Validate(%+v) = %v, want valid=%v", test.locale, err, test.valid)
+		}
+		if _, err := NewParserWithOptions("=1", ParseOptions{Locale: &test.locale}).Parse(); (err == nil) != test.valid {
+			t.Errorf("Parse with %+v = %v, want valid=%v", test.locale, err, test.valid)
+		}
+	}
+}
+
+func TestLocalizedFunctions(t *testing.T) {
+	tests := []struct {
+		locale   *Locale
+		src      string
+		expected string
+		english  string
+	}{
+		{LocaleDE, "=SUMME(A1;B1)", "FunCallExpr(Name: SUM, Arguments: [CellExpr(A1), CellExpr(B1)])", "=SUM(A1,B1)"},
+		{LocaleDE, "=sverweis(A1;B:C;2;FALSCH)", "FunCallExpr(Name: VLOOKUP, Arguments: [CellExpr(A1), RangeExpr(CellExpr(B):CellExpr(C)), LiteralExpr(Value: 2), LiteralExpr(Value: FALSE)])", "=VLOOKUP(A1,B:C,2,FALSE)"},
+		{LocaleDE, "=WAHR", "LiteralExpr(Value: TRUE)", "=TRUE"},
+		{LocaleDE, "={WAHR.FALSCH;1.2}", "ArrayExpr([LiteralExpr(Value: TRUE), LiteralExpr(Value: FALSE)], [LiteralExpr(Value: 1), LiteralExpr(Value: 2)])", "={TRUE,FALSE;1,2}"},
+		{LocaleDE, "=BEREICH.VERSCHIEBEN(A1;1;1)", "FunCallExpr(Name: OFFSET, Arguments: [CellExpr(A1), LiteralExpr(Value: 1), LiteralExpr(Value: 1)])", "=OFFSET(A1,1,1)"},
+		{LocaleFR, "=ET(VRAI;{FAUX\\1})", "FunCallExpr(Name: AND, Arguments: [LiteralExpr(Value: TRUE), ArrayExpr([LiteralExpr(Value: FALSE), LiteralExpr(Value: 1)])])", "=AND(TRUE,{FALSE,1})"},
+		{LocaleDE, "=ZÄHLENWENN(A:A;\">1,5\")", "FunCallExpr(Name: COUNTIF, Arguments: [RangeExpr(CellExpr(A):CellExpr(A)), LiteralExpr(Value: \">1,5\")])", "=COUNTIF(A:A,\">1,5\")"},
+		{LocaleFR, "=SI(A1>0,5;NB.SI(B:B;1);{1\\2;3\\4})", "FunCallExpr(Name: IF, Arguments: [BinaryExpr(Left: CellExpr(A1), Operator: >, Right: LiteralExpr(Value: 0,5)), FunCallExpr(Name: COUNTIF, Arguments: [RangeExpr(CellExpr(B):CellExpr(B)), LiteralExpr(Value: 1)]), ArrayExpr([LiteralExpr(Value: 1), LiteralExpr(Value: 2)], [LiteralExpr(Value: 3), LiteralExpr(Value: 4)])])", "=IF(A1>0.5,COUNTIF(B:B,1),{1,2;3,4})"},
+		{LocaleES, "=SI.ERROR(BUSCARV(A1;B:C;2;0);\"\")", "FunCallExpr(Name: IFERROR, Arguments: [FunCallExpr(Name: VLOOKUP, Arguments: [CellExpr(A1), RangeExpr(CellExpr(B):CellExpr(C)), LiteralExpr(Value: 2), LiteralExpr(Value: 0)]), LiteralExpr(Value: \"\")])", "=IFERROR(VLOOKUP(A1,B:C,2,0),\"\")"},
+		{LocaleIT, "=SOMMA.SE(A1:A5;\"x\";B1:B5)", "FunCallExpr(Name: SUMIF, Arguments: [RangeExpr(CellExpr(A1):CellExpr(A5)), LiteralExpr(Value: \"x\"), RangeExpr(CellExpr(B1):CellExpr(B5))])", "=SUMIF(A1:A5,\"x\",B1:B5)"},
+		{LocaleNL, "=ALS(EN(A1;B1);1;2)", "FunCallExpr(Name: IF, Arguments: [FunCallExpr(Name: AND, Arguments: [CellExpr(A1), CellExpr(B1)]), LiteralExpr(Value: 1), LiteralExpr(Value: 2)])", "=IF(AND(A1,B1),1,2)"},
+		{LocalePT, "=PROCV(A1;Plan1!A:B;2;0)", "FunCallExpr(Name: VLOOKUP, Arguments: [CellExpr(A1), SheetRefExpr(Sheet: Plan1, Ref: RangeExpr(CellExpr(A):CellExpr(B))), LiteralExpr(Value: 2), LiteralExpr(Value: 0)])", "=VLOOKUP(A1,Plan1!A:B,2,0)"},
+		{LocaleRU, "=СУММ(A1:A3)*2,5", "BinaryExpr(Left: FunCallExpr(Name: SUM, Arguments: [RangeExpr(CellExpr(A1):CellExpr(A3))]), Operator: *, Right: LiteralExpr(Value: 2,5))", "=SUM(A1:A3)*2.5"},
+		{LocaleDE, "=LET(x;1;SUMME(x;x))", "LetExpr(Bindings: [x = LiteralExpr(Value: 1)], Body: FunCallExpr(Name: SUM, Arguments: [IdentExpr(Name: x), IdentExpr(Name: x)]))", "=LET(x,1,SUM(x,x))"},
+		{LocaleDE, "=LET(SUMME;LAMBDA(a;a);SUMME(1))", "LetExpr(Bindings: [SUMME = LambdaExpr(Params: [a], Body: IdentExpr(Name: a))], Body: CallExpr(Callee: IdentExpr(Name: SUMME), Arguments: [LiteralExpr(Value: 1)]))", "=LET(SUMME,LAMBDA(a,a),SUMME(1))"},
+		{LocaleDE, "=_xlfn.XLOOKUP(A1;B:B;C:C)", "FunCallExpr(Name: _xlfn.XLOOKUP, Arguments: [CellExpr(A1), RangeExpr(CellExpr(B):CellExpr(B)), RangeExpr(CellExpr(C):CellExpr(C))])", "=_xlfn.XLOOKUP(A1,B:B,C:C)"},
+	}
+	var english = &Printer{}
+	for _, test := range tests {
+		node, err := NewParserWithOptions(test.src, ParseOptions{Locale: test.locale}).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		if node.String() != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, node.String())
+		}
+		if got := english.Format(node); got != test.english {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.english, got)
+		}
+		// rendering in the source locale gives back the formula, up to the case of names
+		if got := (&Printer{Locale: test.locale}).Format(node); !strings.EqualFold(got, test.src) {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.src, got)
+		}
+	}
+
+	node, err := NewParserWithOptions("=SUMME(A1;B1)", ParseOptions{Locale: LocaleDE}).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if call := node.(FunCallExpr); call.LocalName == nil || call.LocalName.Raw != "SUMME" || call.Name.Start != call.LocalName.Start {
+		t.Errorf("Expected the original SUMME token, got %v", call.LocalName)
+	}
+	if got := (&Printer{Locale: LocaleFR}).Format(node); got != "=SOMME(A1;B1)" {
+		t.Errorf("Expected '=SOMME(A1;B1)', got '%s'", got)
+	}
+
+	node, err = NewParserWithOptions("=WENN(A1; wahr ;FALSCH)", ParseOptions{Locale: LocaleDE}).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if v, err := Evaluate(node, &EvalContext{Resolver: testCells}); err != nil || v.String() != "TRUE" {
+		t.Errorf("Expected TRUE, got %v, %v", v, err)
+	}
+	if lit := node.(FunCallExpr).Arguments[1].(LiteralExpr); lit.LocalValue == nil || lit.LocalValue.Raw != "wahr" || lit.Value.Start != lit.LocalValue.Start {
+		t.Errorf("Expected the original wahr token, got %v", lit.LocalValue)
+	}
+	if got := Source(node); got != "=WENN(A1; wahr ;FALSCH)" {
+		t.Errorf("Expected the source as written, got '%s'", got)
+	}
+	if got := (&Printer{Locale: LocaleFR}).Format(node); got != "=SI(A1;VRAI;FAUX)" {
+		t.Errorf("Expected '=SI(A1;VRAI;FAUX)', got '%s'", got)
+	}
+}
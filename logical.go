@@ -0,0 +1,135 @@
+package excelformulaparser
+
+// Most functions return the first error among their arguments, like the
+// operators do. The functions in this file handle errors themselves: IF only
+// evaluates the chosen value, IFERROR and IFNA replace errors, and the IS
+// functions and ERROR.TYPE inspect them.
+
+func init() {
+	register("IF", 2, 3, ifFunc)
+	register("IFERROR", 2, 2, ifError(func(ErrorKind) bool { return true }))
+	register("IFNA", 2, 2, ifError(func(kind ErrorKind) bool { return kind == ErrorNA }))
+	register("ISERROR", 1, 1, isError(func(ErrorKind) bool { return true }))
+	register("ISERR", 1, 1, isError(func(kind ErrorKind) bool { return kind != ErrorNA }))
+	register("ISNA", 1, 1, isError(func(kind ErrorKind) bool { return kind == ErrorNA }))
+	register("ERROR.TYPE", 1, 1, errorType)
+	register("NA", 0, 0, func(*funcCall) (Value, error) {
+		return NewError(ErrorNA), nil
+	})
+}
+
+// ifFunc implements IF(condition, value_if_true, [value_if_false]). Only the
+// chosen value is evaluated, unless the condition is an array.
+func ifFunc(c *funcCall) (Value, error) {
+	cond, err := c.value(0)
+	if err != nil {
+		return Value{}, err
+	}
+	if cond.Kind == ValueArray {
+		return c.ifArray(cond)
+	}
+	b, kind := toBool(cond)
+	switch {
+	case kind != 0:
+		return NewError(kind), nil
+	case b:
+		return c.eval(1)
+	case len(c.args) < 3:
+		return NewBool(false), nil
+	default:
+		return c.eval(2)
+	}
+}
+
+// ifArray chooses the values of IF for each element of an array condition,
+// e.g. IF({TRUE,FALSE}, 1, 2) is {1,2}.
+func (c *funcCall) ifArray(cond Value) (Value, error) {
+	then, err := c.value(1)
+	if err != nil {
+		return Value{}, err
+	}
+	var otherwise = NewBool(false)
+	if len(c.args) >= 3 {
+		if otherwise, err = c.value(2); err != nil {
+			return Value{}, err
+		}
+	}
+	return lift([]Value{cond, then, otherwise}, func(v []Value) Value {
+		b, kind := toBool(v[0])
+		switch {
+		case kind != 0:
+			return NewError(kind)
+		case b:
+			return v[1]
+		default:
+			return v[2]
+		}
+	}), nil
+}
+
+// ifError returns IFERROR(value, value_if_error) or IFNA for the errors that
+// match. The value_if_error is only evaluated if there is such an error.
+func ifError(match func(ErrorKind) bool) builtin {
+	var matches = func(v Value) bool {
+		return v.Kind == ValueError && match(v.Error)
+	}
+	return func(c *funcCall) (Value, error) {
+		v, err := c.value(0)
+		if err != nil {
+			return Value{}, err
+		}
+		if !matches(v) && !containsElement(v, matches) {
+			return v, nil
+		}
+		fallback, err := c.value(1)
+		if err != nil || v.Kind != ValueArray {
+			return fallback, err
+		}
+		return broadcast(v, fallback, func(a, b Value) Value {
+			if matches(a) {
+				return b
+			}
+			return a
+		}), nil
+	}
+}
+
+// isError returns ISERROR(value), ISERR or ISNA for the errors that match.
+func isError(match func(ErrorKind) bool) builtin {
+	return func(c *funcCall) (Value, error) {
+		v, err := c.value(0)
+		if err != nil {
+			return Value{}, err
+		}
+		return elementwise(v, func(v Value) Value {
+			return NewBool(v.Kind == ValueError && match(v.Error))
+		}), nil
+	}
+}
+
+// errorType implements ERROR.TYPE(error_val), the number of an error value
+// (e.g., 2 for #DIV/0!), or #N/A if the value is not an error.
+func errorType(c *funcCall) (Value, error) {
+	v, err := c.value(0)
+	if err != nil {
+		return Value{}, err
+	}
+	return elementwise(v, func(v Value) Value {
+		if v.Kind != ValueError {
+			return NewError(ErrorNA)
+		}
+		return NewNumber(float64(v.Error.Code()))
+	}), nil
+}
+
+// containsElement reports whether an element of an array satisfies f.
+func containsElement(v Value, f func(Value) bool) bool {
+	for _, row := range v.Array {
+		for _, element := range row {
+			if f(element) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package excelformulaparser
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLogicalFunctions(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"=IF(TRUE, 1, 2)", "1"},
+		{"=IF(0, 1, 2)", "2"},
+		{"=IF(FALSE, 1)", "FALSE"},
+		{"=IF(FALSE, 1,)", "0"},
+		{"=IF(C1, 1, 2)", "2"},
+		{`=IF("true", 1, 2)`, "1"},
+		{`=IF("yes", 1, 2)`, "#VALUE!"},
+		{"=IF(#REF!, 1, 2)", "#REF!"},
+		{"=IF(TRUE, 1, 1/0)", "1"},
+		{"=IF(FALSE, UNKNOWN(), 2)", "2"},
+		{"=IF(TRUE, A1:A2)", "{1;2}"},
+		{"=IF({TRUE,FALSE,1}, {1,2,3}, 0)", "{1,0,3}"},
+		{"=IF({TRUE;FALSE}, 1/0, \"no\")", `{#DIV/0!;"no"}`},
+		{`=IF({1,"x"}, 1, 2)`, "{1,#VALUE!}"},
+		{"=_xlfn.IFNA(#N/A, 0)", "0"},
+		{"=IFERROR(1/0, \"x\")", `"x"`},
+		{"=IFERROR(1, 1/0)", "1"},
+		{"=IFERROR(C1, 1)", "0"},
+		{"=IFERROR({1,#N/A,#REF!}, 0)", "{1,0,0}"},
+		{"=IFERROR({1,#N/A}, {10,20})", "{1,20}"},
+		{"=IFNA(#N/A, 0)", "0"},
+		{"=IFNA(#DIV/0!, 0)", "#DIV/0!"},
+		{"=IFNA({#N/A,#NUM!}, 0)", "{0,#NUM!}"},
+		{"=ISERROR(#N/A)", "TRUE"},
+		{"=ISERROR(1)", "FALSE"},
+		{"=ISERROR(UNKNOWN())", "TRUE"},
+		{"=ISERR(#N/A)", "FALSE"},
+		{"=ISERR(1/0)", "TRUE"},
+		{"=ISNA(NA())", "TRUE"},
+		{"=ISNA(#VALUE!)", "FALSE"},
+		{"=ISERROR(A1:B2+1)", "{FALSE,FALSE;FALSE,TRUE}"},
+		{"=ERROR.TYPE(1/0)", "2"},
+		{"=ERROR.TYPE(#N/A)", "7"},
+		{"=ERROR.TYPE(1)", "#N/A"},
+		{"=ERROR.TYPE({#NULL!,#CALC!})", "{1,14}"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		v, err := Evaluate(node, &EvalContext{Resolver: testCells})
+		if err != nil {
+			t.Errorf("For input '%s', unexpected error: %v", test.src, err)
+			continue
+		}
+		if got := v.String(); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+	}
+}
+
+func TestFunctionArgumentCount(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"=IF(TRUE)", "(1,2): IF requires at least 2 arguments, got 1"},
+		{"=1+IFERROR(1, 2, 3)", "(1,4): IFERROR accepts at most 2 arguments, got 3"},
+		{"=NA(1)", "(1,2): NA accepts at most 0 arguments, got 1"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		_, err = Evaluate(node, nil)
+		var evalErr *EvalError
+		if !errors.As(err, &evalErr) || err.Error() != test.expected {
+			t.Errorf("For input '%s', expected error '%s', got '%v'", test.src, test.expected, err)
+		}
+	}
+}
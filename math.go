@@ -0,0 +1,363 @@
+package excelformulaparser
+
+import (
+	"math"
+	"math/rand"
+)
+
+func init() {
+	register("SUM", 1, 255, aggregate(func(x []float64) Value {
+		var sum float64
+		for _, n := range x {
+			sum += n
+		}
+		return finite(sum)
+	}))
+	register("SUMSQ", 1, 255, aggregate(func(x []float64) Value {
+		var sum float64
+		for _, n := range x {
+			sum += n * n
+		}
+		return finite(sum)
+	}))
+	register("PRODUCT", 1, 255, aggregate(func(x []float64) Value {
+		if len(x) == 0 {
+			return NewNumber(0)
+		}
+		var product = 1.0
+		for _, n := range x {
+			product *= n
+		}
+		return finite(product)
+	}))
+	register("SUMPRODUCT", 1, 255, sumProduct)
+	register("GCD", 1, 255, aggregate(gcd))
+	register("LCM", 1, 255, aggregate(lcm))
+
+	register("ROUND", 2, 2, numeric(func(x []float64) Value {
+		return roundDigits(x[0], x[1], math.Round)
+	}))
+	register("ROUNDUP", 2, 2, numeric(func(x []float64) Value {
+		return roundDigits(x[0], x[1], func(y float64) float64 {
+			return math.Copysign(math.Ceil(math.Abs(y)), y)
+		})
+	}))
+	register("ROUNDDOWN", 2, 2, numeric(func(x []float64) Value {
+		return roundDigits(x[0], x[1], math.Trunc)
+	}))
+	register("TRUNC", 1, 2, numeric(func(x []float64) Value {
+		return roundDigits(x[0], optional(x, 1, 0), math.Trunc)
+	}))
+	register("INT", 1, 1, numeric(func(x []float64) Value {
+		return NewNumber(math.Floor(x[0]))
+	}))
+	register("MROUND", 2, 2, numeric(func(x []float64) Value {
+		switch n, m := x[0], x[1]; {
+		case m == 0:
+			return NewNumber(0)
+		case n*m < 0:
+			return NewError(ErrorNum)
+		default:
+			return finite(math.Round(round15(n/m)) * m)
+		}
+	}))
+	register("MOD", 2, 2, numeric(func(x []float64) Value {
+		var n, d = x[0], x[1]
+		if d == 0 {
+			return NewError(ErrorDiv0)
+		}
+		return finite(n - d*math.Floor(round15(n/d)))
+	}))
+	register("ABS", 1, 1, numeric(func(x []float64) Value {
+		return NewNumber(math.Abs(x[0]))
+	}))
+	register("SIGN", 1, 1, numeric(func(x []float64) Value {
+		switch {
+		case x[0] > 0:
+			return NewNumber(1)
+		case x[0] < 0:
+			return NewNumber(-1)
+		}
+		return NewNumber(0)
+	}))
+	register("POWER", 2, 2, numeric(func(x []float64) Value {
+		return binaryOp(Exponentiation, NewNumber(x[0]), NewNumber(x[1]))
+	}))
+	register("SQRT", 1, 1, numeric(func(x []float64) Value {
+		if x[0] < 0 {
+			return NewError(ErrorNum)
+		}
+		return NewNumber(math.Sqrt(x[0]))
+	}))
+	register("EXP", 1, 1, numeric(func(x []float64) Value {
+		return finite(math.Exp(x[0]))
+	}))
+	register("LN", 1, 1, numeric(func(x []float64) Value {
+		return logarithm(x[0], math.E)
+	}))
+	register("LOG", 1, 2, numeric(func(x []float64) Value {
+		return logarithm(x[0], optional(x, 1, 10))
+	}))
+	register("LOG10", 1, 1, numeric(func(x []float64) Value {
+		return logarithm(x[0], 10)
+	}))
+	register("PI", 0, 0, func(*funcCall) (Value, error) {
+		return NewNumber(math.Pi), nil
+	})
+
+	register("SIN", 1, 1, numeric(func(x []float64) Value {
+		return finite(math.Sin(x[0]))
+	}))
+	register("COS", 1, 1, numeric(func(x []float64) Value {
+		return finite(math.Cos(x[0]))
+	}))
+	register("TAN", 1, 1, numeric(func(x []float64) Value {
+		return finite(math.Tan(x[0]))
+	}))
+	register("ASIN", 1, 1, numeric(func(x []float64) Value {
+		return finite(math.Asin(x[0])) // NaN, so #NUM!, outside [-1, 1]
+	}))
+	register("ACOS", 1, 1, numeric(func(x []float64) Value {
+		return finite(math.Acos(x[0]))
+	}))
+	register("ATAN", 1, 1, numeric(func(x []float64) Value {
+		return finite(math.Atan(x[0]))
+	}))
+	register("ATAN2", 2, 2, numeric(func(x []float64) Value {
+		if x[0] == 0 && x[1] == 0 {
+			return NewError(ErrorDiv0)
+		}
+		return finite(math.Atan2(x[1], x[0])) // ATAN2(x_num, y_num)
+	}))
+
+	register("CEILING", 2, 2, numeric(func(x []float64) Value {
+		switch n, s := x[0], x[1]; {
+		case s == 0:
+			return NewNumber(0)
+		case n > 0 && s < 0:
+			return NewError(ErrorNum)
+		default:
+			return multiple(n, s, math.Ceil)
+		}
+	}))
+	register("FLOOR", 2, 2, numeric(func(x []float64) Value {
+		switch n, s := x[0], x[1]; {
+		case s == 0:
+			return NewError(ErrorDiv0)
+		case n > 0 && s < 0:
+			return NewError(ErrorNum)
+		default:
+			return multiple(n, s, math.Floor)
+		}
+	}))
+	register("CEILING.MATH", 1, 3, numeric(func(x []float64) Value {
+		var n, s, mode = x[0], math.Abs(optional(x, 1, 1)), optional(x, 2, 0)
+		if n < 0 && mode != 0 { // away from zero
+			return multiple(n, s, math.Floor)
+		}
+		return multiple(n, s, math.Ceil)
+	}))
+	register("FLOOR.MATH", 1, 3, numeric(func(x []float64) Value {
+		var n, s, mode = x[0], math.Abs(optional(x, 1, 1)), optional(x, 2, 0)
+		if n < 0 && mode != 0 { // toward zero
+			return multiple(n, s, math.Ceil)
+		}
+		return multiple(n, s, math.Floor)
+	}))
+	var ceilingPrecise = numeric(func(x []float64) Value {
+		return multiple(x[0], math.Abs(optional(x, 1, 1)), math.Ceil)
+	})
+	register("CEILING.PRECISE", 1, 2, ceilingPrecise)
+	register("ISO.CEILING", 1, 2, ceilingPrecise)
+	register("FLOOR.PRECISE", 1, 2, numeric(func(x []float64) Value {
+		return multiple(x[0], math.Abs(optional(x, 1, 1)), math.Floor)
+	}))
+
+	register("FACT", 1, 1, numeric(func(x []float64) Value {
+		var n = math.Trunc(x[0])
+		if n < 0 {
+			return NewError(ErrorNum)
+		}
+		var fact = 1.0
+		for i := 2.0; i <= n && !math.IsInf(fact, 0); i++ {
+			fact *= i
+		}
+		return finite(fact)
+	}))
+	register("COMBIN", 2, 2, numeric(func(x []float64) Value {
+		var n, k = math.Trunc(x[0]), math.Trunc(x[1])
+		if n < 0 || k < 0 || n < k {
+			return NewError(ErrorNum)
+		}
+		k = min(k, n-k)
+		var combin = 1.0
+		for i := 1.0; i <= k && !math.IsInf(combin, 0); i++ {
+			combin = combin * (n - k + i) / i
+		}
+		return finite(math.Round(combin))
+	}))
+
+	register("RAND", 0, 0, func(c *funcCall) (Value, error) {
+		return NewNumber(c.e.rand()), nil
+	})
+	register("RANDBETWEEN", 2, 2, func(c *funcCall) (Value, error) {
+		return numeric(func(x []float64) Value {
+			var bottom, top = math.Ceil(x[0]), math.Floor(x[1])
+			if bottom > top {
+				return NewError(ErrorNum)
+			}
+			return NewNumber(bottom + math.Floor(c.e.rand()*(top-bottom+1)))
+		})(c)
+	})
+}
+
+// aggregate returns a built-in function of the numbers of its arguments, e.g.
+// SUM (see funcCall.numbers).
+func aggregate(f func(x []float64) Value) builtin {
+	return func(c *funcCall) (Value, error) {
+		x, kind, err := c.numbers()
+		if err != nil {
+			return Value{}, err
+		}
+		if kind != 0 {
+			return NewError(kind), nil
+		}
+		return f(x), nil
+	}
+}
+
+// optional returns x[i], or def if the argument is omitted.
+func optional(x []float64, i int, def float64) float64 {
+	if i < len(x) {
+		return x[i]
+	}
+	return def
+}
+
+// roundDigits rounds x to a number of decimal digits (truncated to an integer,
+// negative to the left of the decimal point) with round, e.g. math.Round to
+// round half away from zero like ROUND. Like Excel, x is rounded to 15
+// significant digits first, so that ROUND(2.675, 2) is 2.68.
+func roundDigits(x, digits float64, round func(float64) float64) Value {
+	var d = math.Trunc(digits)
+	switch {
+	case d > 15:
+		return NewNumber(x)
+	case d < -308:
+		return NewNumber(0)
+	case d >= 0:
+		var p = math.Pow10(int(d))
+		return finite(round(round15(x*p)) / p)
+	default:
+		var p = math.Pow10(int(-d))
+		return finite(round(round15(x/p)) * p)
+	}
+}
+
+// multiple rounds n to a multiple of significance with round (math.Ceil or
+// math.Floor), or returns 0 if significance is 0.
+func multiple(n, significance float64, round func(float64) float64) Value {
+	if significance == 0 {
+		return NewNumber(0)
+	}
+	return finite(round(round15(n/significance)) * significance)
+}
+
+// logarithm returns the logarithm of x to a base.
+func logarithm(x, base float64) Value {
+	switch {
+	case x <= 0 || base <= 0:
+		return NewError(ErrorNum)
+	case base == 1:
+		return NewError(ErrorDiv0)
+	case base == 10:
+		return finite(math.Log10(x))
+	default:
+		return finite(math.Log(x) / math.Log(base))
+	}
+}
+
+// sumProduct implements SUMPRODUCT(array1, [array2], ...), the sum of the
+// products of corresponding elements. Elements other than numbers are 0, and
+// the arrays must have the same size.
+func sumProduct(c *funcCall) (Value, error) {
+	var arrays = make([]Value, len(c.args))
+	for i := range c.args {
+		v, err := c.value(i)
+		if err != nil {
+			return Value{}, err
+		}
+		if v.Kind == ValueError {
+			return v, nil
+		}
+		arrays[i] = v
+	}
+	var rows, cols = arrayRows(arrays[0]), arrayCols(arrays[0])
+	for _, v := range arrays[1:] {
+		if arrayRows(v) != rows || arrayCols(v) != cols {
+			return NewError(ErrorValue), nil
+		}
+	}
+	var sum float64
+	for i := range rows {
+		for j := range cols {
+			var product = 1.0
+			for _, v := range arrays {
+				element, _ := arrayElement(v, i, j)
+				switch element.Kind {
+				case ValueError:
+					return element, nil
+				case ValueNumber:
+					product *= element.Number
+				default:
+					product = 0
+				}
+			}
+			sum += product
+		}
+	}
+	return finite(sum), nil
+}
+
+// gcd returns the greatest common divisor of the integer parts of x.
+func gcd(x []float64) Value {
+	var result float64
+	for _, n := range x {
+		if n = math.Trunc(n); n < 0 || n >= 1<<53 {
+			return NewError(ErrorNum)
+		}
+		result = euclid(result, n)
+	}
+	return NewNumber(result)
+}
+
+// lcm returns the least common multiple of the integer parts of x.
+func lcm(x []float64) Value {
+	var result = 1.0
+	for _, n := range x {
+		if n = math.Trunc(n); n < 0 || n >= 1<<53 {
+			return NewError(ErrorNum)
+		}
+		if n == 0 {
+			return NewNumber(0)
+		}
+		result = result / euclid(result, n) * n
+	}
+	return finite(result)
+}
+
+// euclid returns the greatest common divisor of two integers.
+func euclid(a, b float64) float64 {
+	for b != 0 {
+		a, b = b, math.Mod(a, b)
+	}
+	return a
+}
+
+// rand returns a random number in [0, 1) from EvalContext.Rand.
+func (e *evaluator) rand() float64 {
+	if e.ctx.Rand != nil {
+		return e.ctx.Rand()
+	}
+	return rand.Float64()
+}
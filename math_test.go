@@ -0,0 +1,171 @@
+package excelformulaparser
+
+import "testing"
+
+func TestMathFunctions(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		// flattening: direct arguments are converted, references and arrays only count numbers
+		{"=SUM(1, 2, 3)", "6"},
+		{"=SUM(A1:A3)", "6"},
+		{"=SUM(A1:B3)", "16"},
+		{`=SUM("1", TRUE, C1)`, "2"},
+		{`=SUM({1,"2",TRUE})`, "1"},
+		{"=SUM(B2)", "0"},
+		{`=SUM("x")`, "#VALUE!"},
+		{"=SUM((A1:A3,B1))", "16"},
+		{"=SUM(A1:A3, #N/A, 1/0)", "#N/A"},
+		{"=SUM({1,#DIV/0!})", "#DIV/0!"},
+		{"=SUM(A1:A3*2)", "12"},
+		{"=SUM(Sheet2!A1, 1)", "101"},
+		{"=SUM(0.1, 0.2)=0.3", "TRUE"},
+		{"=PRODUCT(A1:A3, 4)", "24"},
+		{"=PRODUCT(C1:C2)", "0"},
+		{"=SUMSQ(3, {4})", "25"},
+		{"=SUMPRODUCT({1,2,3}, {4,5,6})", "32"},
+		{"=SUMPRODUCT(A1:A3, A1:A3)", "14"},
+		{"=SUMPRODUCT(B1:B3, {1;1;1})", "10"},
+		{"=SUMPRODUCT({1,2}, {1,2,3})", "#VALUE!"},
+		{"=SUMPRODUCT({1,#N/A})", "#N/A"},
+		{"=GCD(24, 36, 8)", "4"},
+		{"=GCD(0, 5)", "5"},
+		{"=GCD(-1, 2)", "#NUM!"},
+		{"=LCM(4, 6, 10.5)", "60"},
+		{"=LCM(4, 0)", "0"},
+		// rounding
+		{"=ROUND(2.5, 0)", "3"},
+		{"=ROUND(-2.5, 0)", "-3"},
+		{"=ROUND(2.675, 2)", "2.68"},
+		{"=ROUND(1234.5, -2)", "1200"},
+		{"=ROUND(1.005, 2.9)", "1.01"},
+		{`=ROUND("1.25", 1)`, "1.3"},
+		{"=ROUND({1.5,2.5}, 0)", "{2,3}"},
+		{"=ROUND(A1:A2/3, 1)", "{0.3;0.7}"},
+		{"=ROUNDUP(1.21, 1)", "1.3"},
+		{"=ROUNDUP(-1.21, 1)", "-1.3"},
+		{"=ROUNDUP(0.3*3, 1)", "0.9"},
+		{"=ROUNDDOWN(-1.29, 1)", "-1.2"},
+		{"=TRUNC(-8.9)", "-8"},
+		{"=TRUNC(3.14159, 3)", "3.141"},
+		{"=INT(-8.9)", "-9"},
+		{"=MROUND(10, 3)", "9"},
+		{"=MROUND(-10, -3)", "-9"},
+		{"=MROUND(1.3, 0.2)", "1.4"},
+		{"=MROUND(5, -2)", "#NUM!"},
+		{"=CEILING(2.5, 1)", "3"},
+		{"=CEILING(-2.5, -2)", "-4"},
+		{"=CEILING(-2.5, 2)", "-2"},
+		{"=CEILING(1.5, -1)", "#NUM!"},
+		{"=CEILING(0.234, 0.01)", "0.24"},
+		{"=FLOOR(-2.5, -2)", "-2"},
+		{"=FLOOR(-2.5, 2)", "-4"},
+		{"=FLOOR(3.7, 0)", "#DIV/0!"},
+		{"=_xlfn.CEILING.MATH(-5.5, 2)", "-4"},
+		{"=CEILING.MATH(-5.5, 2, -1)", "-6"},
+		{"=CEILING.MATH(24.3, 5)", "25"},
+		{"=FLOOR.MATH(-5.5, 2, -1)", "-4"},
+		{"=FLOOR.MATH(24.3, 5)", "20"},
+		{"=CEILING.PRECISE(-4.1, -2)", "-4"},
+		{"=ISO.CEILING(4.1)", "5"},
+		{"=FLOOR.PRECISE(-3.2, 2)", "-4"},
+		// arithmetic
+		{"=MOD(3, 2)", "1"},
+		{"=MOD(-3, 2)", "1"},
+		{"=MOD(3, -2)", "-1"},
+		{"=MOD(3, 0)", "#DIV/0!"},
+		{"=MOD(5.3, 1)", "0.3"},
+		{"=ABS(-2)", "2"},
+		{"=SIGN(-0.5)", "-1"},
+		{"=SIGN(C1)", "0"},
+		{"=POWER(2, 10)", "1024"},
+		{"=POWER(0, 0)", "#NUM!"},
+		{"=POWER(-8, 1/3)", "#NUM!"},
+		{"=SQRT(16)", "4"},
+		{"=SQRT(-1)", "#NUM!"},
+		{"=EXP(1)", "2.71828182845905"},
+		{"=EXP(1000)", "#NUM!"},
+		{"=LN(EXP(2))", "2"},
+		{"=LN(0)", "#NUM!"},
+		{"=LOG(100)", "2"},
+		{"=LOG(8, 2)", "3"},
+		{"=LOG(8, 1)", "#DIV/0!"},
+		{"=LOG10(1000)", "3"},
+		{"=FACT(5)", "120"},
+		{"=FACT(5.9)", "120"},
+		{"=FACT(0)", "1"},
+		{"=FACT(-1)", "#NUM!"},
+		{"=FACT(171)", "#NUM!"},
+		{"=COMBIN(8, 2)", "28"},
+		{"=COMBIN(60, 30)", "1.18264581564861E+17"},
+		{"=COMBIN(2, 3)", "#NUM!"},
+		// trigonometry
+		{"=PI()", "3.14159265358979"},
+		{"=SIN(PI()/2)", "1"},
+		{"=COS(0)", "1"},
+		{"=TAN(PI()/4)", "1"},
+		{"=ASIN(1)*2=PI()", "TRUE"},
+		{"=ASIN(2)", "#NUM!"},
+		{"=ACOS(-1)=PI()", "TRUE"},
+		{"=ATAN(1)*4=PI()", "TRUE"},
+		{"=ATAN2(1, 1)*4=PI()", "TRUE"},
+		{"=ATAN2(-1, 0)=PI()", "TRUE"},
+		{"=ATAN2(0, 0)", "#DIV/0!"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		v, err := Evaluate(node, &EvalContext{Resolver: testCells})
+		if err != nil {
+			t.Errorf("For input '%s', unexpected error: %v", test.src, err)
+			continue
+		}
+		if got := v.String(); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+	}
+}
+
+func TestRandFunctions(t *testing.T) {
+	tests := []struct {
+		src      string
+		rand     float64
+		expected string
+	}{
+		{"=RAND()", 0.25, "0.25"},
+		{"=RANDBETWEEN(1, 6)", 0, "1"},
+		{"=RANDBETWEEN(1, 6)", 0.5, "4"},
+		{"=RANDBETWEEN(1, 6)", 0.999, "6"},
+		{"=RANDBETWEEN(1.5, 2.5)", 0.9, "2"},
+		{"=RANDBETWEEN(-3, -1)", 0.4, "-2"},
+		{"=RANDBETWEEN(6, 1)", 0.5, "#NUM!"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		var ctx = &EvalContext{Rand: func() float64 { return test.rand }}
+		v, err := Evaluate(node, ctx)
+		if err != nil {
+			t.Errorf("For input '%s', unexpected error: %v", test.src, err)
+			continue
+		}
+		if got := v.String(); got != test.expected {
+			t.Errorf("For input '%s' with %v, expected '%s', got '%s'", test.src, test.rand, test.expected, got)
+		}
+	}
+
+	node, err := NewParser("=RAND()").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if v, err := Evaluate(node, nil); err != nil || v.Kind != ValueNumber || v.Number < 0 || v.Number >= 1 {
+		t.Errorf("Expected a random number in [0, 1), got %v, %v", v, err)
+	}
+}
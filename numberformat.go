@@ -0,0 +1,707 @@
+package excelformulaparser
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// A number format of TEXT has up to four sections separated by ';', for
+// positive numbers, negative numbers, zero and text. A section formats either
+// numbers with the placeholders 0 # ? and . , % E+, fractions like # ?/?, or
+// dates and times with the codes y m d h s, AM/PM and the elapsed time [h]
+// [m] [s]. Text in quotes, characters escaped with '\', currency symbols like
+// [$€-407], and other characters such as $ - ( ) and spaces are copied.
+// Colors like [Red] are ignored, and other bracketed codes such as the
+// condition [<0] are not supported.
+
+// formatTokenKind is the kind of a formatToken.
+type formatTokenKind int
+
+const (
+	formatLiteral     formatTokenKind = iota // Copied text
+	formatDigit                              // A digit placeholder: 0, # or ?
+	formatPoint                              // The decimal point
+	formatComma                              // A thousands separator or scaling
+	formatPercent                            // %
+	formatExponent                           // E+ or E-
+	formatDate                               // A date or time code, e.g. yyyy, mm or AM/PM
+	formatText                               // @, the text of a value
+	formatGeneral                            // General
+	formatUnsupported                        // A bracketed code that TEXT does not support, e.g. [<0]
+)
+
+type formatToken struct {
+	kind formatTokenKind
+	text string
+}
+
+// formatValue implements TEXT(value, format_text).
+func formatValue(v Value, format string) Value {
+	if v.Kind == ValueError {
+		return v
+	}
+	var sections = splitFormat(format)
+	for _, section := range sections {
+		if containsKind(section, formatUnsupported) {
+			return NewError(ErrorValue)
+		}
+	}
+	var x float64
+	switch v.Kind {
+	case ValueBool:
+		return NewString(toText(v))
+	case ValueString:
+		n, ok := textToNumber(v.Text)
+		if !ok {
+			var section []formatToken
+			switch {
+			case len(sections) >= 4:
+				section = sections[3]
+			case len(sections) == 1 && containsKind(sections[0], formatText):
+				section = sections[0]
+			default:
+				return v
+			}
+			return NewString(renderLiterals(section, v.Text))
+		}
+		x = n
+	default:
+		n, kind := toNumber(v)
+		if kind != 0 {
+			return NewError(kind)
+		}
+		x = n
+	}
+
+	var section = sections[0]
+	var sign = ""
+	switch {
+	case x < 0 && len(sections) >= 2:
+		section, x = sections[1], -x
+	case x < 0:
+		sign, x = "-", -x
+	case x == 0 && len(sections) >= 3:
+		section = sections[2]
+	}
+	if containsKind(section, formatDate) {
+		s, ok := formatSerial(x, section)
+		if !ok || sign != "" {
+			return NewError(ErrorValue) // a negative date
+		}
+		return NewString(s)
+	}
+	var s, ok = formatNumberSection(x, section)
+	if !ok {
+		return NewError(ErrorNum) // e.g., 1E308 as a percentage
+	}
+	return NewString(sign + s)
+}
+
+// splitFormat splits a number format into the tokens of its sections.
+func splitFormat(format string) [][]formatToken {
+	var sections = [][]formatToken{nil}
+	var runes = []rune(format)
+	var last = func() *[]formatToken { return &sections[len(sections)-1] }
+	var add = func(kind formatTokenKind, text string) {
+		*last() = append(*last(), formatToken{kind, text})
+	}
+	for i := 0; i < len(runes); i++ {
+		var r = runes[i]
+		var rest = string(runes[i:])
+		switch {
+		case r == ';':
+			sections = append(sections, nil)
+		case r == '"':
+			var j = i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			add(formatLiteral, string(runes[i+1:min(j, len(runes))]))
+			i = j
+		case r == '\\' && i+1 < len(runes):
+			i++
+			add(formatLiteral, string(runes[i]))
+		case r == '_' && i+1 < len(runes): // a space as wide as the next character
+			i++
+			add(formatLiteral, " ")
+		case r == '*' && i+1 < len(runes): // repeats the next character to fill the cell
+			i++
+		case r == '[':
+			var j = i + 1
+			for j < len(runes) && runes[j] != ']' {
+				j++
+			}
+			var code = string(runes[i+1 : min(j, len(runes))])
+			var lower = strings.ToLower(code)
+			i = j
+			switch {
+			case lower != "" && strings.Contains("hms", lower[:1]) && strings.Trim(lower, lower[:1]) == "":
+				add(formatDate, "["+lower+"]") // elapsed time, e.g. [h]
+			case strings.HasPrefix(code, "$"): // a currency symbol and locale, e.g. [$€-407]
+				symbol, _, _ := strings.Cut(code[1:], "-")
+				add(formatLiteral, symbol)
+			case isColor(lower):
+			default:
+				add(formatUnsupported, code)
+			}
+		case r == '0' || r == '#' || r == '?':
+			add(formatDigit, string(r))
+		case r == '.':
+			add(formatPoint, ".")
+		case r == ',':
+			add(formatComma, ",")
+		case r == '%':
+			add(formatPercent, "%")
+		case (r == 'E' || r == 'e') && i+1 < len(runes) && (runes[i+1] == '+' || runes[i+1] == '-'):
+			i++
+			add(formatExponent, "E"+string(runes[i]))
+		case r == '@':
+			add(formatText, "@")
+		case strings.HasPrefix(strings.ToUpper(rest), "GENERAL"):
+			i += len("GENERAL") - 1
+			add(formatGeneral, "General")
+		case strings.HasPrefix(strings.ToUpper(rest), "AM/PM"):
+			add(formatDate, string(runes[i:i+5]))
+			i += 4
+		case strings.HasPrefix(strings.ToUpper(rest), "A/P"):
+			add(formatDate, string(runes[i:i+3]))
+			i += 2
+		case strings.ContainsRune("yYmMdDhHsS", r):
+			var j = i
+			for j < len(runes) && unicode.ToLower(runes[j]) == unicode.ToLower(r) {
+				j++
+			}
+			add(formatDate, strings.ToLower(string(runes[i:j])))
+			i = j - 1
+		default:
+			add(formatLiteral, string(r))
+		}
+	}
+	return sections
+}
+
+// isColor reports whether a lower case bracketed code is a color, e.g. red or
+// color10.
+func isColor(code string) bool {
+	switch code {
+	case "black", "blue", "cyan", "green", "magenta", "red", "white", "yellow":
+		return true
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(code, "color"))
+	return strings.HasPrefix(code, "color") && err == nil && n >= 1 && n <= 56
+}
+
+func containsKind(tokens []formatToken, kind formatTokenKind) bool {
+	for _, token := range tokens {
+		if token.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// renderLiterals renders a section without numbers, replacing @ by text.
+func renderLiterals(section []formatToken, text string) string {
+	var sb strings.Builder
+	for _, token := range section {
+		switch token.kind {
+		case formatText, formatGeneral:
+			sb.WriteString(text)
+		case formatDate:
+		default:
+			sb.WriteString(token.text)
+		}
+	}
+	return sb.String()
+}
+
+// formatNumberSection formats a non-negative number with a section of number
+// placeholders, or returns false if the number is too large.
+func formatNumberSection(x float64, section []formatToken) (string, bool) {
+	if len(section) == 0 {
+		return "", true
+	}
+	if slash := fractionSlash(section); slash >= 0 {
+		return formatFraction(x, section, slash)
+	}
+	var point, exponent = len(section), len(section)
+	var intDigits, fracDigits, lastInt, last = 0, 0, -1, -1
+	var grouping = false
+	for i, token := range section {
+		switch token.kind {
+		case formatPoint:
+			point = min(point, i)
+		case formatExponent:
+			exponent = min(exponent, i)
+		case formatPercent:
+			x *= 100
+		case formatDigit:
+			switch {
+			case i < min(point, exponent):
+				intDigits++
+				lastInt, last = i, i
+			case i < exponent:
+				fracDigits++
+				last = i
+			}
+		case formatGeneral:
+			return renderLiterals(section, formatNumber(x)), true
+		}
+	}
+	if intDigits+fracDigits == 0 {
+		return renderLiterals(section, formatNumber(x)), true // e.g., 1 with @ is 1
+	}
+	for i := 0; i < lastInt; i++ {
+		if section[i].kind == formatComma && containsKind(section[:i], formatDigit) {
+			grouping = true // e.g., #,##0
+		}
+	}
+	for i := last + 1; i < len(section) && section[i].kind == formatComma; i++ {
+		x /= 1000 // e.g., 0.0, for thousands
+	}
+	if math.IsInf(x, 0) {
+		return "", false
+	}
+
+	var e = 0
+	if exponent < len(section) && x != 0 {
+		e = int(math.Floor(math.Log10(round15(x))))
+		if strings.Contains(tokensText(section[:point]), "#") && intDigits > 1 {
+			e = floorDiv(e, intDigits) * intDigits // engineering notation, e.g. ##0.0E+0
+		} else {
+			e -= max(intDigits, 1) - 1
+		}
+		x /= math.Pow10(e)
+	}
+	var digits string
+	if x >= 1e15 { // no decimals are left, e.g. 1E308 with #,##0.00
+		digits = largeDigits(x, fracDigits)
+	} else {
+		var rounded = roundDigits(x, float64(fracDigits), math.Round).Number
+		if exponent < len(section) && rounded >= math.Pow10(max(intDigits, 1)) { // e.g., 9.999 as 10.00E+00
+			x, e = x/10, e+1
+			rounded = roundDigits(x, float64(fracDigits), math.Round).Number
+		}
+		digits = strconv.FormatFloat(rounded, 'f', fracDigits, 64)
+	}
+	var integer, fraction, _ = strings.Cut(digits, ".")
+	if integer == "0" {
+		integer = ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fillInteger(section[:min(point, exponent)], integer, grouping))
+	if point < exponent {
+		sb.WriteString(".")
+		sb.WriteString(fillFraction(section[point+1:exponent], fraction))
+	}
+	if exponent < len(section) {
+		var sign = "+"
+		switch {
+		case e < 0:
+			sign = "-"
+		case section[exponent].text == "E-":
+			sign = "" // E- only shows the sign of negative exponents
+		}
+		sb.WriteString("E" + sign)
+		sb.WriteString(fillInteger(section[exponent+1:], strconv.Itoa(max(e, -e)), false))
+	}
+	return sb.String(), true
+}
+
+// largeDigits returns the digits of x >= 1E15 and a number of zero decimals.
+// Like Excel, only 15 significant digits are kept, e.g. 1E20 has 21 digits
+// rather than the exact 100000000000000000000 of its binary value.
+func largeDigits(x float64, decimals int) string {
+	var mantissa, exponent, _ = strings.Cut(strconv.FormatFloat(x, 'e', 14, 64), "e")
+	var e, _ = strconv.Atoi(exponent)
+	var digits = strings.Replace(mantissa, ".", "", 1) + strings.Repeat("0", e-14)
+	if decimals > 0 {
+		digits += "." + strings.Repeat("0", decimals)
+	}
+	return digits
+}
+
+// fractionSlash returns the index of the '/' of a fraction section, which
+// follows the digit placeholders of the numerator (e.g., # ?/?), or -1.
+func fractionSlash(section []formatToken) int {
+	for i, token := range section {
+		if token.kind == formatLiteral && token.text == "/" && i > 0 && section[i-1].kind == formatDigit {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatFraction formats a non-negative number with a fraction section: an
+// optional integer part, the numerator, '/' at slash, and the denominator,
+// which has digit placeholders for its largest value (e.g., ?? up to 99) or
+// is fixed (e.g., ?/8). For example, 3.5 with # ?/? is 3 1/2.
+func formatFraction(x float64, section []formatToken, slash int) (string, bool) {
+	var numerator = slash
+	for numerator > 0 && section[numerator-1].kind == formatDigit {
+		numerator--
+	}
+	var end, fixed = slash + 1, 0
+	for end < len(section) && isFixedDenominator(section[end], fixed) {
+		fixed = fixed*10 + int(section[end].text[0]-'0')
+		end++
+	}
+	for fixed == 0 && end < len(section) && section[end].kind == formatDigit {
+		end++
+	}
+
+	var mixed = containsKind(section[:numerator], formatDigit)
+	var integer, rest = 0.0, x
+	if mixed {
+		integer = math.Floor(x)
+		rest = x - integer
+	}
+	if rest >= 1e15 || math.IsInf(integer, 0) {
+		return "", false
+	}
+	var num, den int
+	if fixed > 0 {
+		num, den = int(math.Round(rest*float64(fixed))), fixed
+	} else {
+		num, den = closestFraction(rest, int(math.Pow10(min(end-slash-1, 7)))-1)
+	}
+	if mixed && num == den { // e.g., 0.99 with # ?/? is 1
+		integer, num = integer+1, 0
+	}
+
+	var sb strings.Builder
+	if mixed {
+		var digits = strconv.FormatFloat(integer, 'f', 0, 64)
+		if integer == 0 && num != 0 {
+			digits = ""
+		}
+		sb.WriteString(fillInteger(section[:numerator], digits, false))
+	} else {
+		sb.WriteString(renderLiterals(section[:numerator], ""))
+	}
+	if mixed && num == 0 {
+		sb.WriteString(strings.Repeat(" ", end-numerator)) // the fraction is left out, e.g. 3 with # ?/?
+	} else {
+		var denominator = strconv.Itoa(den)
+		if fixed == 0 { // zeros pad on the left, and spaces on the right
+			var lead, trail string
+			for _, token := range section[slash+1+min(len(denominator), end-slash-1) : end] {
+				switch token.text {
+				case "0":
+					lead += "0"
+				case "?":
+					trail += " "
+				}
+			}
+			denominator = lead + denominator + trail
+		}
+		sb.WriteString(fillInteger(section[numerator:slash], strconv.Itoa(num), false))
+		sb.WriteString("/" + denominator)
+	}
+	sb.WriteString(renderLiterals(section[end:], ""))
+	return sb.String(), true
+}
+
+// isFixedDenominator reports whether a token is a digit of a fixed
+// denominator, which starts with 1 to 9 (e.g., the 10 of ?/10).
+func isFixedDenominator(token formatToken, denominator int) bool {
+	switch {
+	case token.kind == formatLiteral:
+		return len(token.text) == 1 && token.text[0] >= '1' && token.text[0] <= '9'
+	default:
+		return token.kind == formatDigit && token.text == "0" && denominator > 0
+	}
+}
+
+// closestFraction returns the fraction closest to x >= 0 with a denominator
+// up to maxDen, from the continued fraction of x.
+func closestFraction(x float64, maxDen int) (num, den int) {
+	var p0, q0, p1, q1 = 0.0, 1.0, 1.0, 0.0
+	var y = x
+	for {
+		var a = math.Floor(y)
+		var q2 = q0 + a*q1
+		if q2 > float64(maxDen) {
+			break
+		}
+		p0, q0, p1, q1 = p1, q1, p0+a*p1, q2
+		if y == a {
+			break
+		}
+		y = 1 / (y - a)
+	}
+	// the best semiconvergent may be closer than the last convergent
+	var k = math.Floor((float64(maxDen) - q0) / q1)
+	var p2, q2 = p0 + k*p1, q0 + k*q1
+	if math.Abs(x-p2/q2) < math.Abs(x-p1/q1) {
+		return int(p2), int(q2)
+	}
+	return int(p1), int(q1)
+}
+
+// fillInteger fills the digit placeholders of the integer part with digits
+// from right to left. The leftmost placeholder takes the remaining digits.
+func fillInteger(tokens []formatToken, digits string, grouping bool) string {
+	var parts []string // in reverse order
+	var placeholders = 0
+	for _, token := range tokens {
+		if token.kind == formatDigit {
+			placeholders++
+		}
+	}
+	var count = 0
+	var emit = func(digit string) {
+		if grouping && count > 0 && count%3 == 0 {
+			parts = append(parts, ",")
+		}
+		parts = append(parts, digit)
+		count++
+	}
+	for i := len(tokens) - 1; i >= 0; i-- {
+		var token = tokens[i]
+		switch token.kind {
+		case formatDigit:
+			placeholders--
+			switch {
+			case placeholders == 0 && digits != "":
+				for j := len(digits) - 1; j >= 0; j-- {
+					emit(digits[j : j+1])
+				}
+				digits = ""
+			case digits != "":
+				emit(digits[len(digits)-1:])
+				digits = digits[:len(digits)-1]
+			case token.text == "0":
+				emit("0")
+			case token.text == "?":
+				parts = append(parts, " ")
+			}
+		case formatLiteral:
+			parts = append(parts, token.text)
+		case formatPercent:
+			parts = append(parts, "%")
+		}
+	}
+	slices.Reverse(parts)
+	return strings.Join(parts, "")
+}
+
+// fillFraction fills the digit placeholders of the fractional part with
+// digits from left to right. Trailing zeros are dropped for # and shown as
+// spaces for ?.
+func fillFraction(tokens []formatToken, digits string) string {
+	var placeholders []string
+	for _, token := range tokens {
+		if token.kind == formatDigit {
+			placeholders = append(placeholders, token.text)
+		}
+	}
+	var shown = len(placeholders)
+	for shown > 0 && placeholders[shown-1] != "0" && digits[shown-1] == '0' {
+		shown--
+	}
+	var sb strings.Builder
+	var n = 0
+	for _, token := range tokens {
+		switch token.kind {
+		case formatDigit:
+			switch {
+			case n < shown:
+				sb.WriteByte(digits[n])
+			case token.text == "?":
+				sb.WriteString(" ")
+			}
+			n++
+		case formatLiteral, formatPercent:
+			sb.WriteString(token.text)
+		}
+	}
+	return sb.String()
+}
+
+func tokensText(tokens []formatToken) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString(token.text)
+	}
+	return sb.String()
+}
+
+func floorDiv(a, b int) int {
+	return int(math.Floor(float64(a) / float64(b)))
+}
+
+var (
+	monthNames = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	dayNames   = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+// formatSerial formats a serial number with a section of date and time codes,
+// or returns false if it is not a valid date.
+func formatSerial(serial float64, section []formatToken) (string, bool) {
+	if serial < 0 || serial >= 2958466 { // after 9999-12-31
+		return "", false
+	}
+	var days = math.Floor(serial)
+	var seconds = int(math.Round((serial - days) * 86400))
+	if seconds == 86400 {
+		days, seconds = days+1, 0
+	}
+	var year, month, day = serialDate(int(days))
+	var weekday = (int(days) + 6) % 7 // 1 is a Sunday, as if 1900 was a leap year
+	var hour, minute, second = seconds / 3600, seconds / 60 % 60, seconds % 60
+	var elapsed = int(days)*86400 + seconds
+
+	var ampm = containsDateCode(section, "am/pm") || containsDateCode(section, "a/p")
+	var sb strings.Builder
+	for i, token := range section {
+		if token.kind != formatDate {
+			if token.kind != formatText && token.kind != formatGeneral {
+				sb.WriteString(token.text)
+			}
+			continue
+		}
+		if strings.HasPrefix(token.text, "[") { // elapsed time, e.g. [h] is 36 for 1.5
+			var value = elapsed
+			switch token.text[1] {
+			case 'h':
+				value /= 3600
+			case 'm':
+				value /= 60
+			}
+			var digits = strconv.Itoa(value)
+			sb.WriteString(strings.Repeat("0", max(len(token.text)-2-len(digits), 0)) + digits)
+			continue
+		}
+		switch code := dateCode(token.text); code {
+		case "y", "yy":
+			sb.WriteString(pad2(year % 100))
+		case "yyyy":
+			sb.WriteString(strconv.Itoa(year))
+		case "m", "mm":
+			var value = month
+			if isMinute(section, i) {
+				value = minute
+			}
+			if code == "m" {
+				sb.WriteString(strconv.Itoa(value))
+			} else {
+				sb.WriteString(pad2(value))
+			}
+		case "mmm":
+			sb.WriteString(monthNames[month-1][:3])
+		case "mmmm":
+			sb.WriteString(monthNames[month-1])
+		case "mmmmm":
+			sb.WriteString(monthNames[month-1][:1])
+		case "d":
+			sb.WriteString(strconv.Itoa(day))
+		case "dd":
+			sb.WriteString(pad2(day))
+		case "ddd":
+			sb.WriteString(dayNames[weekday][:3])
+		case "dddd":
+			sb.WriteString(dayNames[weekday])
+		case "h", "hh":
+			var h = hour
+			if ampm {
+				h = (hour+11)%12 + 1
+			}
+			if code == "h" {
+				sb.WriteString(strconv.Itoa(h))
+			} else {
+				sb.WriteString(pad2(h))
+			}
+		case "s":
+			sb.WriteString(strconv.Itoa(second))
+		case "ss":
+			sb.WriteString(pad2(second))
+		case "am/pm":
+			sb.WriteString(meridiem(hour, "AM", "PM", token.text[0] == 'a'))
+		case "a/p":
+			sb.WriteString(meridiem(hour, "A", "P", token.text[0] == 'a'))
+		}
+	}
+	return sb.String(), true
+}
+
+// serialDate returns the date of a serial number in the 1900 date system, in
+// which 0 is 1900-01-00 and 60 is the nonexistent 1900-02-29 (see dateSerial).
+func serialDate(serial int) (year, month, day int) {
+	switch {
+	case serial == 0:
+		return 1900, 1, 0
+	case serial == 60:
+		return 1900, 2, 29
+	case serial > 60:
+		serial-- // after the 1900-02-29 of Excel
+	}
+	var date = time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC).AddDate(0, 0, serial)
+	return date.Year(), int(date.Month()), date.Day()
+}
+
+// isMinute reports whether the m or mm code at i means minutes: it follows
+// an hour code or precedes a second code.
+func isMinute(section []formatToken, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		if section[j].kind == formatDate {
+			if strings.HasPrefix(strings.TrimPrefix(section[j].text, "["), "h") { // e.g., [h]:mm
+				return true
+			}
+			break
+		}
+	}
+	for j := i + 1; j < len(section); j++ {
+		if section[j].kind == formatDate {
+			return strings.HasPrefix(strings.TrimPrefix(section[j].text, "["), "s")
+		}
+	}
+	return false
+}
+
+func containsDateCode(section []formatToken, code string) bool {
+	for _, token := range section {
+		if token.kind == formatDate && dateCode(token.text) == code {
+			return true
+		}
+	}
+	return false
+}
+
+// dateCode returns the lower case code of a date token, shortening long runs
+// of a letter to the longest code (e.g., yyyyy is yyyy).
+func dateCode(text string) string {
+	var code = strings.ToLower(text)
+	var longest = map[byte]int{'y': 4, 'm': 5, 'd': 4, 'h': 2, 's': 2}[code[0]]
+	if longest > 0 && len(code) > longest {
+		code = code[:longest]
+	}
+	if code == "yyy" {
+		code = "yyyy"
+	}
+	return code
+}
+
+// meridiem returns am or pm for an hour, in lower case if lower is true.
+func meridiem(hour int, am, pm string, lower bool) string {
+	var s = am
+	if hour >= 12 {
+		s = pm
+	}
+	if lower {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+func pad2(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
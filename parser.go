@@ -2,23 +2,53 @@ package excelformulaparser
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
+// ParseOptions configures how a Parser reads formulas.
+type ParseOptions struct {
+	R1C1   bool    // Parse references in R1C1 notation (e.g., R1C1, R[-1]C[2]) instead of A1 notation
+	Locale *Locale // The separators of the formula syntax, nil for DefaultLocale
+}
+
 type Parser struct {
-	lexer     *lexer
-	token     *Token
-	lookahead *Token
+	lexer      *lexer
+	token      *Token
+	lookahead  []*Token
+	options    ParseOptions
+	separators separators
+	scopes     []map[string]*Token // LET names and LAMBDA parameters, keyed by upper case name
+	array      bool                // Parsing the elements of an array constant, where whitespace is not an operator
 }
 
 func NewParser(src string) *Parser {
+	return NewParserWithOptions(src, ParseOptions{})
+}
+
+func NewParserWithOptions(src string, options ParseOptions) *Parser {
+	if options.Locale == nil {
+		options.Locale = DefaultLocale
+	}
+	var l = newLexer(src)
+	l.r1c1 = options.R1C1
+	l.decimal = options.Locale.DecimalSeparator
+	l.booleans = options.Locale.Booleans
+	l.arrayColumn = options.Locale.ArrayColumnSeparator
 	return &Parser{
-		lexer: newLexer(src),
+		lexer:      l,
+		options:    options,
+		separators: options.Locale.separators(),
 	}
 }
 
 func (p *Parser) Parse() (Node, error) {
+	if err := p.options.Locale.Validate(); err != nil {
+		return nil, err
+	}
 	if err := p.advance(); err != nil {
 		return nil, err
 	}
@@ -26,9 +56,14 @@ func (p *Parser) Parse() (Node, error) {
 		return nil, nil // No tokens to parse
 	}
 	if p.token.Type == Equal {
+		var equal = p.token
 		if err := p.advance(); err != nil {
 			return nil, err
 		}
+		if p.token != nil {
+			// the '=' prefix is kept as trivia of the first token
+			p.token.Leading = append(append(equal.Leading, equal), p.token.Leading...)
+		}
 	}
 	res, err := p.comparison()
 	if err != nil {
@@ -210,15 +245,22 @@ func (p *Parser) negation() (Node, error) {
 		if err != nil {
 			return nil, err
 		}
-		if lit, ok := right.(LiteralExpr); ok && lit.Value.Type == Number {
-			if isDigit([]rune(lit.Value.Raw)[0]) { // combine '-' / '+' with number literal
-				lit.start = op.Start                   // Adjust start position to the operator
-				lit.Value.Raw = op.Raw + lit.Value.Raw // Prepend the operator to the literal value
+		if lit, ok := right.(LiteralExpr); ok && lit.Value.Type == Number && len(lit.Value.Leading) == 0 {
+			if first := lit.Value.Raw[0]; first != '-' && first != '+' { // combine '-' / '+' with number literal
+				var value = *lit.Value // copy the token instead of modifying the scanned one
+				value.Start = op.Start
+				value.Raw = op.Raw + value.Raw // Prepend the operator to the literal value
+				value.Leading = op.Leading
+				lit.start = op.Start // Adjust start position to the operator
+				lit.Value = &value
+				if op.Type == Minus {
+					lit.Number = -lit.Number
+				}
 				return lit, nil
 			}
 		}
 		return UnaryExpr{
-			baseNode: newBaseNode(op.Start, op.End),
+			baseNode: newBaseNode(op.Start, right.End()),
 			Operator: op,
 			Operand:  right,
 		}, nil
@@ -236,7 +278,7 @@ func (p *Parser) implicitIntersection() (Node, error) {
 		if err := p.advance(); err != nil { // consume the token
 			return nil, err
 		}
-		operand, err := p.rangeExpr()
+		operand, err := p.intersection()
 		if err != nil {
 			return nil, err
 		}
@@ -246,11 +288,68 @@ func (p *Parser) implicitIntersection() (Node, error) {
 			Operand:  operand,
 		}, nil
 	}
-	return p.rangeExpr()
+	return p.intersection()
+}
+
+// intersection parses the whitespace intersection operator (e.g., A1:C5 B2:D8, Sales Q1).
+// Whitespace is only an operator when it separates two references, otherwise it is ignored.
+func (p *Parser) intersection() (Node, error) {
+	var left, err = p.spill()
+	if err != nil {
+		return nil, err
+	}
+	for p.token != nil && p.token.space() != nil && !p.array && isReference(left) {
+		if ok, err := p.startsReference(); err != nil || !ok {
+			return left, err
+		}
+		var op = p.token.space()
+		op.Type = Intersection
+		p.token.Leading = p.token.Leading[:len(p.token.Leading)-1] // the whitespace is the operator, not trivia
+		if len(p.token.Leading) == 0 {
+			p.token.Leading = nil
+		}
+		var right, err = p.spill()
+		if err != nil {
+			return nil, err
+		}
+		if !isReference(right) {
+			return nil, newParseError(right.Start(), "expected a reference after the intersection operator")
+		}
+		left = BinaryExpr{
+			baseNode: newBaseNode(left.Start(), right.End()),
+			Left:     left,
+			Operator: op,
+			Right:    right,
+		}
+	}
+	return left, nil
+}
+
+// spill parses the postfix spilled range operator (e.g., A2#, Sheet2!B4#).
+func (p *Parser) spill() (Node, error) {
+	var left, err = p.rangeExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.token != nil && p.token.Type == Spill {
+		var op = p.token
+		if !isSpillAnchor(left) {
+			return nil, newParseError(op.Start, "the spilled range operator requires a cell or a name")
+		}
+		if err := p.advance(); err != nil { // consume the '#' token
+			return nil, err
+		}
+		return UnaryExpr{
+			baseNode: newBaseNode(left.Start(), op.End),
+			Operator: op,
+			Operand:  left,
+		}, nil
+	}
+	return left, nil
 }
 
 func (p *Parser) rangeExpr() (Node, error) {
-	var left, err = p.primary()
+	var left, err = p.call()
 	if err != nil {
 		return nil, err
 	}
@@ -270,7 +369,7 @@ func (p *Parser) rangeExpr() (Node, error) {
 				return nil, err
 			}
 			colons = append(colons, op)
-			var right, err = p.primary()
+			var right, err = p.call()
 			if err != nil {
 				return nil, err
 			}
@@ -290,10 +389,39 @@ func (p *Parser) rangeExpr() (Node, error) {
 	return left, nil
 }
 
+// call parses the invocation of an expression result, e.g. LAMBDA(a,b,a+b)(1,2).
+func (p *Parser) call() (Node, error) {
+	var callee, err = p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.token != nil && p.token.Type == ParenOpen && p.token.space() == nil && isCallable(callee) {
+		paranOpen, arguments, separators, paranClose, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		callee = CallExpr{
+			baseNode:   newBaseNode(callee.Start(), paranClose.End),
+			Callee:     callee,
+			ParenOpen:  paranOpen,
+			Arguments:  arguments,
+			Separators: separators,
+			ParenClose: paranClose,
+		}
+	}
+	return callee, nil
+}
+
 func (p *Parser) tryConvertToCellExpr(node Node) (Node, error) {
 	if node, ok := node.(CellExpr); ok {
 		return node, nil // Already a CellExpr, no conversion needed
 	}
+	switch node.(type) {
+	case SheetRefExpr, Sheet3DRefExpr, ExternalRefExpr:
+		return node, nil // e.g., the end of A1:Sheet1!B2
+	case R1C1Expr:
+		return node, nil // e.g., C[3]:C[5]
+	}
 	if node, ok := node.(IdentExpr); ok {
 		col := colNameToIndex(node.Name.Raw)
 		if col < 0 {
@@ -339,33 +467,88 @@ func (p *Parser) primary() (Node, error) {
 		return nil, newParseError(p.lexer.pos, "unexpected end of input")
 	}
 	tk := p.token
+	if isSheetNameToken(tk) {
+		var ok, err = p.atSheetPrefix()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return p.sheetRef()
+		}
+	}
 	switch p.token.Type {
 	case ParenOpen:
 		return p.parenthesized()
-	case String, Number, BoolLiteral, EValue:
+	case BracketOpen:
+		return p.bracketedRef()
+	case Number:
+		value, err := p.parseNumber(tk.Raw)
+		if err != nil {
+			return nil, newParseError(tk.Start, "invalid number %s: %s", tk.Raw, err.Error())
+		}
 		if err := p.advance(); err != nil { // consume the token
 			return nil, err
 		}
 		return LiteralExpr{
 			baseNode: newBaseNode(tk.Start, tk.End),
 			Value:    tk,
+			Number:   value,
+		}, nil
+	case EValue:
+		info, ok := LookupErrorValue(tk.Raw)
+		if !ok {
+			return nil, newParseError(tk.Start, "unrecognized error value %s", tk.Raw)
+		}
+		if err := p.advance(); err != nil { // consume the token
+			return nil, err
+		}
+		return LiteralExpr{
+			baseNode:  newBaseNode(tk.Start, tk.End),
+			Value:     tk,
+			ErrorKind: info.Kind,
+		}, nil
+	case String, BoolLiteral:
+		if err := p.advance(); err != nil { // consume the token
+			return nil, err
+		}
+		var localValue *Token
+		if canonical, ok := p.options.Locale.canonicalBoolean(tk.Raw); tk.Type == BoolLiteral && ok {
+			// e.g., the German WAHR becomes TRUE
+			localValue = tk
+			tk = &Token{Start: tk.Start, End: tk.End, Type: BoolLiteral, Raw: canonical}
+		}
+		return LiteralExpr{
+			baseNode:   newBaseNode(tk.Start, tk.End),
+			Value:      tk,
+			LocalValue: localValue,
 		}, nil
 	case Ident:
 		var peek, err = p.peek()
 		if err != nil {
 			return nil, err
 		}
-		if peek != nil && peek.Type == ParenOpen {
+		if peek != nil && peek.Type == ParenOpen && peek.space() == nil { // Name (B1) is an intersection
 			return p.functionCall()
 		}
+		if peek != nil && peek.Type == BracketOpen {
+			return p.tableRef()
+		}
 		if err := p.advance(); err != nil { // consume the token
 			return nil, err
 		}
 		return IdentExpr{
 			baseNode: newBaseNode(tk.Start, tk.End),
 			Name:     tk,
+			Binding:  p.lookup(tk.Raw),
 		}, nil
 	case Cell:
+		var peek, err = p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if peek != nil && peek.Type == BracketOpen {
+			return p.tableRef() // e.g., Table1[Col], the table name looks like a cell
+		}
 		// parse the cell token to extract row and column information
 		result, err := parseCell(tk.Raw)
 		if err != nil {
@@ -382,6 +565,20 @@ func (p *Parser) primary() (Node, error) {
 			Col:         result.col,
 			ColAbsolute: result.colAbsolute,
 		}, nil
+	case R1C1Reference:
+		row, col, err := parseR1C1(tk.Raw)
+		if err != nil {
+			return nil, newParseError(tk.Start, "invalid R1C1 reference: %s", err.Error())
+		}
+		if err := p.advance(); err != nil { // consume the token
+			return nil, err
+		}
+		return R1C1Expr{
+			baseNode: newBaseNode(tk.Start, tk.End),
+			Ident:    tk,
+			Row:      row,
+			Col:      col,
+		}, nil
 	case AbsoluteRow:
 		row, err := strconv.Atoi(tk.Raw[1:]) // Skip the '$' character
 		if err != nil {
@@ -418,20 +615,288 @@ func (p *Parser) primary() (Node, error) {
 	}
 }
 
+// atSheetPrefix reports whether the current token starts a sheet prefix,
+// either Sheet1! or the 3D form Sheet1:Sheet3!.
+func (p *Parser) atSheetPrefix() (bool, error) {
+	var peek, err = p.peekN(0)
+	if err != nil || peek == nil {
+		return false, err
+	}
+	if peek.Type == Exclamation {
+		return true, nil
+	}
+	if peek.Type != Colon {
+		return false, nil
+	}
+	last, err := p.peekN(1)
+	if err != nil || last == nil || !isSheetNameToken(last) {
+		return false, err
+	}
+	exclamation, err := p.peekN(2)
+	if err != nil || exclamation == nil {
+		return false, err
+	}
+	return exclamation.Type == Exclamation, nil
+}
+
+// sheetRef parses a sheet-qualified reference such as Sheet1!A1 or 'My Sheet'!B2:C9,
+// and 3D references spanning multiple sheets such as Jan:Dec!B2 or 'Jan 2024:Dec 2024'!C4.
+func (p *Parser) sheetRef() (Node, error) {
+	first, colon, last, err := p.sheetRange()
+	if err != nil {
+		return nil, err
+	}
+	exclamation, err := p.exclamation(first)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := p.qualifiedRef(exclamation)
+	if err != nil {
+		return nil, err
+	}
+	if colon != nil {
+		return Sheet3DRefExpr{
+			baseNode:        newBaseNode(first.Start, ref.End()),
+			FirstSheet:      unquoteSheetName(first.Raw),
+			LastSheet:       unquoteSheetName(last.Raw),
+			FirstSheetToken: first,
+			Colon:           colon,
+			LastSheetToken:  last,
+			Exclamation:     exclamation,
+			Ref:             ref,
+		}, nil
+	}
+	var sheet = unquoteSheetName(first.Raw)
+	if isQuotedSheetName(first.Raw) {
+		if path, workbook, rest, ok := splitExternalName(sheet); ok {
+			// e.g., 'C:\dir\[Book.xlsx]Sheet1'!A1
+			var firstSheet, lastSheet, _ = strings.Cut(rest, ":")
+			return ExternalRefExpr{
+				baseNode:    newBaseNode(first.Start, ref.End()),
+				Path:        path,
+				Workbook:    workbook,
+				Sheet:       firstSheet,
+				LastSheet:   lastSheet,
+				Prefix:      []*Token{first},
+				Exclamation: exclamation,
+				Ref:         ref,
+			}, nil
+		}
+		if firstSheet, lastSheet, ok := strings.Cut(sheet, ":"); ok {
+			// Sheet names cannot contain ':', so a quoted name with a colon is a sheet range
+			return Sheet3DRefExpr{
+				baseNode:        newBaseNode(first.Start, ref.End()),
+				FirstSheet:      firstSheet,
+				LastSheet:       lastSheet,
+				FirstSheetToken: first,
+				Exclamation:     exclamation,
+				Ref:             ref,
+			}, nil
+		}
+	}
+	return SheetRefExpr{
+		baseNode:    newBaseNode(first.Start, ref.End()),
+		Sheet:       sheet,
+		SheetToken:  first,
+		Exclamation: exclamation,
+		Ref:         ref,
+	}, nil
+}
+
+// bracketedRef parses a reference starting with '[', which is either an
+// external workbook reference or a structured reference without table name.
+func (p *Parser) bracketedRef() (Node, error) {
+	var bracketed, err = p.bracketed()
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	switch []rune(bracketed.Raw)[1] {
+	case '[', '@', '#': // e.g., [[#Headers],[Col]], [@Col], [#Totals]
+	default:
+		if ok, err = p.atWorkbookSuffix(); err != nil {
+			return nil, err
+		}
+	}
+	if ok {
+		return p.externalRef(bracketed)
+	}
+	return p.structuredRef(nil, bracketed)
+}
+
+// atWorkbookSuffix reports whether the tokens after a bracketed workbook name
+// complete an external reference, i.e. Sheet1!, Jan:Dec! or just !.
+func (p *Parser) atWorkbookSuffix() (bool, error) {
+	if p.token == nil {
+		return false, nil
+	}
+	if p.token.Type == Exclamation {
+		return true, nil
+	}
+	if !isSheetNameToken(p.token) || isQuotedSheetName(p.token.Raw) {
+		return false, nil
+	}
+	return p.atSheetPrefix()
+}
+
+// tableRef parses a structured reference qualified with a table name (e.g., Table1[Col]).
+func (p *Parser) tableRef() (Node, error) {
+	var table = p.token
+	if err := p.advance(); err != nil { // consume the table name token
+		return nil, err
+	}
+	specifier, err := p.bracketed()
+	if err != nil {
+		return nil, err
+	}
+	return p.structuredRef(table, specifier)
+}
+
+// structuredRef builds a structured reference from its optional table name
+// and its bracketed specifier (e.g., [[#Headers],[Col A]:[Col C]]).
+func (p *Parser) structuredRef(table, specifier *Token) (Node, error) {
+	items, firstColumn, lastColumn, err := parseStructuredSpecifier(specifier.Raw)
+	if err != nil {
+		return nil, newParseError(specifier.Start, "invalid structured reference %s: %s", specifier.Raw, err.Error())
+	}
+	var start = specifier.Start
+	if table != nil {
+		start = table.Start
+	}
+	return StructuredRefExpr{
+		baseNode:    newBaseNode(start, specifier.End),
+		Table:       table,
+		Specifier:   specifier,
+		Items:       items,
+		FirstColumn: firstColumn,
+		LastColumn:  lastColumn,
+	}, nil
+}
+
+// bracketed scans the text enclosed by the current '[' token and advances past it.
+func (p *Parser) bracketed() (*Token, error) {
+	if p.token == nil || p.token.Type != BracketOpen {
+		return nil, newParseError(p.lexer.pos, "expected '['")
+	}
+	if len(p.lookahead) > 0 {
+		return nil, newParseError(p.token.Start, "unexpected token %s (type=%v)", p.token.Raw, p.token.Type)
+	}
+	bracketed, err := p.lexer.bracketed(p.token)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.advance(); err != nil { // consume the bracketed text
+		return nil, err
+	}
+	return bracketed, nil
+}
+
+// externalRef parses a reference into another workbook, either by file name
+// ([Book.xlsx]Sheet1!A1) or by OOXML external link index ([1]Sheet1!A1).
+// The sheet may be omitted for workbook-level names (e.g., [1]!Name).
+func (p *Parser) externalRef(workbook *Token) (Node, error) {
+	var prefix = []*Token{workbook}
+	var sheet, lastSheet string
+	if p.token != nil && isSheetNameToken(p.token) && !isQuotedSheetName(p.token.Raw) {
+		first, colon, last, err := p.sheetRange()
+		if err != nil {
+			return nil, err
+		}
+		sheet = first.Raw
+		prefix = append(prefix, first)
+		if colon != nil {
+			lastSheet = last.Raw
+			prefix = append(prefix, colon, last)
+		}
+	}
+	exclamation, err := p.exclamation(prefix[len(prefix)-1])
+	if err != nil {
+		return nil, err
+	}
+	ref, err := p.qualifiedRef(exclamation)
+	if err != nil {
+		return nil, err
+	}
+	return ExternalRefExpr{
+		baseNode:    newBaseNode(workbook.Start, ref.End()),
+		Workbook:    workbook.Raw[1 : len(workbook.Raw)-1],
+		Sheet:       sheet,
+		LastSheet:   lastSheet,
+		Prefix:      prefix,
+		Exclamation: exclamation,
+		Ref:         ref,
+	}, nil
+}
+
+// sheetRange parses a sheet name, optionally followed by ':' and the last
+// sheet name of a 3D reference. colon and last are nil for a single sheet.
+func (p *Parser) sheetRange() (first, colon, last *Token, err error) {
+	first = p.token
+	if err = p.advance(); err != nil { // consume the sheet name token
+		return
+	}
+	if p.token != nil && p.token.Type == Colon {
+		colon = p.token
+		if err = p.advance(); err != nil { // consume the ':' token
+			return
+		}
+		if p.token == nil || !isSheetNameToken(p.token) {
+			err = newParseError(colon.End, "expected a sheet name after ':'")
+			return
+		}
+		last = p.token
+		if err = p.advance(); err != nil { // consume the last sheet name token
+			return
+		}
+	}
+	return
+}
+
+// exclamation consumes the '!' that must follow prev.
+func (p *Parser) exclamation(prev *Token) (*Token, error) {
+	if p.token == nil || p.token.Type != Exclamation {
+		return nil, newParseError(prev.End, "expected '!' after %s", prev.Raw)
+	}
+	var exclamation = p.token
+	if err := p.advance(); err != nil { // consume the '!' token
+		return nil, err
+	}
+	return exclamation, nil
+}
+
+// qualifiedRef parses the reference following the '!' of a sheet prefix.
+func (p *Parser) qualifiedRef(exclamation *Token) (Node, error) {
+	if p.token == nil {
+		return nil, newParseError(exclamation.End, "unexpected end of input, expected a reference after '!'")
+	}
+	ref, err := p.rangeExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !isSheetQualifiable(ref) {
+		return nil, newParseError(ref.Start(), "expected a reference after '!', got %T", ref)
+	}
+	return ref, nil
+}
+
 func (p *Parser) arrayExpr() (Node, error) {
 	var braceOpen = p.token
+	defer func(array bool) { p.array = array }(p.array)
+	p.array = true
 	if err := p.advance(); err != nil { // consume the '{' token
 		return nil, err
 	}
 	var row_index = 0
 	var values = [][]Node{}
+	var separators []*Token
 	for p.token != nil && p.token.Type != BraceClose {
 		if row_index > 0 {
-			// expected a semicolon as row separator
-			if p.token.Type != Semicolon {
-				return nil, newParseError(p.token.Start, "expected a semicolon to separate rows in array, got %s (type=%v)", p.token.Raw, p.token.Type)
+			// expected a row separator (e.g., ';')
+			if p.token.Type != p.separators.arrayRow {
+				return nil, newParseError(p.token.Start, "expected '%c' to separate rows in array, got %s (type=%v)", p.options.Locale.ArrayRowSeparator, p.token.Raw, p.token.Type)
 			}
-			if err := p.advance(); err != nil { // consume the ';' token
+			separators = append(separators, p.token)
+			if err := p.advance(); err != nil { // consume the row separator
 				return nil, err
 			}
 			if p.token == nil {
@@ -440,13 +905,14 @@ func (p *Parser) arrayExpr() (Node, error) {
 		}
 		var col_index = 0
 		var row_values = []Node{}
-		for p.token != nil && (p.token.Type != Semicolon && p.token.Type != BraceClose) {
+		for p.token != nil && (p.token.Type != p.separators.arrayRow && p.token.Type != BraceClose) {
 			if col_index > 0 {
-				// expected a comma as col separator
-				if p.token.Type != Comma {
-					return nil, newParseError(p.token.Start, "expected a comma to separate columns in array, got %s (type=%v)", p.token.Raw, p.token.Type)
+				// expected a column separator (e.g., ',')
+				if p.token.Type != p.separators.arrayColumn {
+					return nil, newParseError(p.token.Start, "expected '%c' to separate columns in array, got %s (type=%v)", p.options.Locale.ArrayColumnSeparator, p.token.Raw, p.token.Type)
 				}
-				if err := p.advance(); err != nil { // consume the ',' token
+				separators = append(separators, p.token)
+				if err := p.advance(); err != nil { // consume the column separator
 					return nil, err
 				}
 				if p.token == nil {
@@ -478,6 +944,7 @@ func (p *Parser) arrayExpr() (Node, error) {
 		baseNode:   newBaseNode(braceOpen.Start, braceClose.End),
 		BraceOpen:  braceOpen,
 		Elements:   values,
+		Separators: separators,
 		BraceClose: braceClose,
 	}, nil
 }
@@ -496,45 +963,257 @@ func (p *Parser) functionCall() (Node, error) {
 	if p.token == nil || p.token.Type != ParenOpen {
 		return nil, newParseError(name.Start, "expected '(' after function name")
 	}
-	var paranOpen = p.token
-	if err := p.advance(); err != nil { // consume the '(' token
+	var localName *Token
+	if p.lookup(name.Raw) == nil {
+		if canonical, ok := p.options.Locale.canonicalName(name.Raw); ok {
+			// e.g., the German SUMME(A1;B1) becomes SUM(A1;B1)
+			localName = name
+			name = &Token{Start: name.Start, End: name.End, Type: Ident, Raw: canonical}
+		}
+	}
+	switch canonicalFunctionName(name.Raw) {
+	case "LET":
+		return p.letExpr(name)
+	case "LAMBDA":
+		return p.lambdaExpr(name)
+	}
+	paranOpen, arguments, separators, paranClose, err := p.arguments()
+	if err != nil {
 		return nil, err
 	}
-	var arguments []Node
-	for p.token != nil && p.token.Type != ParenClose {
-		var arg, err = p.comparison()
-		if err != nil {
-			return nil, err
+	if binding := p.lookup(name.Raw); binding != nil {
+		// e.g., the f(3) in LET(f, LAMBDA(x, x*2), f(3))
+		return CallExpr{
+			baseNode: newBaseNode(name.Start, paranClose.End),
+			Callee: IdentExpr{
+				baseNode: newBaseNode(name.Start, name.End),
+				Name:     name,
+				Binding:  binding,
+			},
+			ParenOpen:  paranOpen,
+			Arguments:  arguments,
+			Separators: separators,
+			ParenClose: paranClose,
+		}, nil
+	}
+	return FunCallExpr{
+		baseNode:   newBaseNode(name.Start, paranClose.End),
+		Name:       name,
+		LocalName:  localName,
+		ParanOpen:  paranOpen,
+		Arguments:  arguments,
+		Separators: separators,
+		ParanClose: paranClose,
+	}, nil
+}
+
+// arguments parses a parenthesized, comma separated argument list.
+// Omitted arguments (e.g., the second one of IF(A1,,0)) become EmptyArgExpr.
+func (p *Parser) arguments() (paranOpen *Token, arguments []Node, separators []*Token, paranClose *Token, err error) {
+	paranOpen = p.token
+	if err = p.advance(); err != nil { // consume the '(' token
+		return
+	}
+	var empty = p.token != nil && p.token.Type == ParenClose // e.g., SUM()
+	for !empty {
+		if p.token == nil {
+			err = newParseError(paranOpen.Start, "unexpected end of input, expected ')' to close function call")
+			return
+		}
+		var arg Node
+		if p.token.Type == p.separators.argument || p.token.Type == ParenClose {
+			arg = EmptyArgExpr{baseNode: newBaseNode(p.token.Start, p.token.Start)}
+		} else if arg, err = p.comparison(); err != nil {
+			return
 		}
 		arguments = append(arguments, arg)
 		if p.token == nil {
-			return nil, newParseError(name.Start, "unexpected end of input, expected ')' to close function call")
+			err = newParseError(paranOpen.Start, "unexpected end of input, expected ')' to close function call")
+			return
+		}
+		if p.token.Type == ParenClose {
+			break
+		}
+		if p.token.Type != p.separators.argument {
+			err = newParseError(p.token.Start, "expected '%c' or ')' in argument list, got %s (type=%v)", p.options.Locale.ArgSeparator, p.token.Raw, p.token.Type)
+			return
+		}
+		separators = append(separators, p.token)
+		if err = p.advance(); err != nil { // consume the argument separator
+			return
+		}
+	}
+	if p.token == nil || p.token.Type != ParenClose {
+		err = newParseError(p.lexer.pos, "expected ')' to close function call")
+		return
+	}
+	paranClose = p.token
+	err = p.advance() // consume the ')' token
+	return
+}
+
+// letExpr parses LET(name1, value1, [name2, value2, ...], calculation).
+// Each name is visible in the values after it and in the calculation.
+func (p *Parser) letExpr(name *Token) (Node, error) {
+	var parenOpen = p.token
+	if err := p.advance(); err != nil { // consume the '(' token
+		return nil, err
+	}
+	p.pushScope()
+	defer p.popScope()
+	var bindings []LetBinding
+	var separators []*Token
+	var body Node
+	for body == nil {
+		decl, separator, err := p.declaration()
+		if err != nil {
+			return nil, err
 		}
-		if p.token.Type == Comma {
-			if err := p.advance(); err != nil { // consume the ',' token
+		if decl == nil {
+			if body, err = p.comparison(); err != nil {
 				return nil, err
 			}
-			if p.token == nil || p.token.Type == ParenClose {
-				return nil, newParseError(name.Start, "unexpected end of input, expected argument after ','")
-			}
+			break
+		}
+		value, err := p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		if p.token == nil || p.token.Type != p.separators.argument {
+			return nil, newParseError(value.End(), "expected '%c' and a calculation after the value of %s", p.options.Locale.ArgSeparator, decl.Raw)
+		}
+		separators = append(separators, separator, p.token)
+		if err := p.advance(); err != nil { // consume the argument separator
+			return nil, err
 		}
+		p.declare(decl)
+		bindings = append(bindings, LetBinding{Name: decl, Value: value})
 	}
-	if p.token == nil || p.token.Type != ParenClose {
-		return nil, newParseError(p.lexer.pos, "expected ')' to close function call")
+	if len(bindings) == 0 {
+		return nil, newParseError(name.Start, "%s requires at least one name and value", name.Raw)
 	}
-	var paranClose = p.token
-	if err := p.advance(); err != nil { // consume the ')' token
+	parenClose, err := p.closeParen(name)
+	if err != nil {
 		return nil, err
 	}
-	return FunCallExpr{
-		baseNode:   newBaseNode(name.Start, paranClose.End),
+	return LetExpr{
+		baseNode:   newBaseNode(name.Start, parenClose.End),
 		Name:       name,
-		ParanOpen:  paranOpen,
-		Arguments:  arguments,
-		ParanClose: paranClose,
+		ParenOpen:  parenOpen,
+		Bindings:   bindings,
+		Body:       body,
+		Separators: separators,
+		ParenClose: parenClose,
+	}, nil
+}
+
+// lambdaExpr parses LAMBDA([parameter1, parameter2, ...], calculation).
+func (p *Parser) lambdaExpr(name *Token) (Node, error) {
+	var parenOpen = p.token
+	if err := p.advance(); err != nil { // consume the '(' token
+		return nil, err
+	}
+	p.pushScope()
+	defer p.popScope()
+	var params []*Token
+	var separators []*Token
+	for {
+		param, separator, err := p.declaration()
+		if err != nil {
+			return nil, err
+		}
+		if param == nil {
+			break
+		}
+		if p.scopes[len(p.scopes)-1][strings.ToUpper(param.Raw)] != nil {
+			return nil, newParseError(param.Start, "duplicate parameter %s", param.Raw)
+		}
+		p.declare(param)
+		params = append(params, param)
+		separators = append(separators, separator)
+	}
+	body, err := p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	parenClose, err := p.closeParen(name)
+	if err != nil {
+		return nil, err
+	}
+	return LambdaExpr{
+		baseNode:   newBaseNode(name.Start, parenClose.End),
+		Name:       name,
+		ParenOpen:  parenOpen,
+		Params:     params,
+		Body:       body,
+		Separators: separators,
+		ParenClose: parenClose,
 	}, nil
 }
 
+// declaration consumes a name declared by LET or LAMBDA, that is an
+// identifier followed by the argument separator. It returns nil if the next argument is not one.
+func (p *Parser) declaration() (decl, separator *Token, err error) {
+	if p.token == nil {
+		return nil, nil, newParseError(p.lexer.pos, "unexpected end of input")
+	}
+	if p.token.Type != Ident {
+		return nil, nil, nil
+	}
+	peek, err := p.peek()
+	if err != nil || peek == nil || peek.Type != p.separators.argument {
+		return nil, nil, err
+	}
+	decl = p.token
+	if err = p.advance(); err != nil { // consume the name
+		return nil, nil, err
+	}
+	separator = p.token
+	if err = p.advance(); err != nil { // consume the argument separator
+		return nil, nil, err
+	}
+	return decl, separator, nil
+}
+
+// closeParen consumes the ')' closing the call of name.
+func (p *Parser) closeParen(name *Token) (*Token, error) {
+	if p.token == nil {
+		return nil, newParseError(p.lexer.pos, "unexpected end of input, expected ')' to close %s", name.Raw)
+	}
+	if p.token.Type != ParenClose {
+		return nil, newParseError(p.token.Start, "expected ')' to close %s, got %s (type=%v)", name.Raw, p.token.Raw, p.token.Type)
+	}
+	var parenClose = p.token
+	if err := p.advance(); err != nil { // consume the ')' token
+		return nil, err
+	}
+	return parenClose, nil
+}
+
+func (p *Parser) pushScope() {
+	p.scopes = append(p.scopes, map[string]*Token{})
+}
+
+func (p *Parser) popScope() {
+	p.scopes = p.scopes[:len(p.scopes)-1]
+}
+
+func (p *Parser) declare(name *Token) {
+	p.scopes[len(p.scopes)-1][strings.ToUpper(name.Raw)] = name
+}
+
+// lookup returns the LET name or LAMBDA parameter declaration that name
+// refers to, or nil if it is not a local name (e.g., a defined name).
+func (p *Parser) lookup(name string) *Token {
+	var key = strings.ToUpper(name)
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if decl, ok := p.scopes[i][key]; ok {
+			return decl
+		}
+	}
+	return nil
+}
+
 func (p *Parser) parenthesized() (Node, error) {
 	if p.token == nil || p.token.Type != ParenOpen {
 		return nil, newParseError(p.token.Start, "expected '('")
@@ -544,11 +1223,14 @@ func (p *Parser) parenthesized() (Node, error) {
 	if err := p.advance(); err != nil { // consume the '(' token
 		return nil, err
 	}
-	var expr, err = p.comparison()
+	var expr, err = p.union()
 	if err != nil {
 		return nil, err
 	}
-	if p.token == nil || p.token.Type != ParenClose {
+	if p.token == nil {
+		return nil, newParseError(p.lexer.pos, "unexpected end of input, expected ')'")
+	}
+	if p.token.Type != ParenClose {
 		return nil, newParseError(p.token.Start, "expected ')'")
 	}
 	var parenClose = p.token
@@ -564,10 +1246,42 @@ func (p *Parser) parenthesized() (Node, error) {
 	}, nil
 }
 
+// union parses the comma separated references of a parenthesized group
+// (e.g., the (A1:A3,C1:C3) in SUM((A1:A3,C1:C3))), where the argument separator is the union operator.
+func (p *Parser) union() (Node, error) {
+	var left, err = p.comparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.token != nil && p.token.Type == p.separators.argument {
+		var op = p.token
+		if !isReference(left) {
+			return nil, newParseError(left.Start(), "expected a reference before the union operator")
+		}
+		if err := p.advance(); err != nil { // consume the ',' token
+			return nil, err
+		}
+		var right, err = p.comparison()
+		if err != nil {
+			return nil, err
+		}
+		if !isReference(right) {
+			return nil, newParseError(right.Start(), "expected a reference after the union operator")
+		}
+		left = BinaryExpr{
+			baseNode: newBaseNode(left.Start(), right.End()),
+			Left:     left,
+			Operator: op,
+			Right:    right,
+		}
+	}
+	return left, nil
+}
+
 func (p *Parser) advance() error {
-	if p.lookahead != nil {
-		p.token = p.lookahead
-		p.lookahead = nil
+	if len(p.lookahead) > 0 {
+		p.token = p.lookahead[0]
+		p.lookahead = p.lookahead[1:]
 		return nil
 	}
 	token, err := p.lexer.next()
@@ -583,18 +1297,22 @@ func (p *Parser) advance() error {
 }
 
 func (p *Parser) peek() (*Token, error) {
-	if p.lookahead != nil {
-		return p.lookahead, nil
-	}
-	token, err := p.lexer.next()
-	if err != nil {
-		if errors.Is(err, io.EOF) {
-			return nil, nil
+	return p.peekN(0)
+}
+
+// peekN returns the n-th token after the current one (starting from 0) without consuming it.
+func (p *Parser) peekN(n int) (*Token, error) {
+	for len(p.lookahead) <= n {
+		token, err := p.lexer.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			return nil, err
 		}
-		return nil, err
+		p.lookahead = append(p.lookahead, token)
 	}
-	p.lookahead = token
-	return p.lookahead, nil
+	return p.lookahead[n], nil
 }
 
 type parseCellResult struct {
@@ -612,32 +1330,32 @@ func parseCell(cell string) (result *parseCellResult, err error) {
 	var offset = 0
 	result = &parseCellResult{}
 	if runes[offset] == '$' {
-		result.rowAbsolute = true
+		result.colAbsolute = true
 		offset++ // consume the '$'
 	}
-	var rowStarts = offset
-	var rowEnds = offset
+	var colStarts = offset
+	var colEnds = offset
 	for offset < len(runes) && isASCIILetter(runes[offset]) {
 		offset += 1
-		rowEnds += 1
+		colEnds += 1
 	}
-	if rowEnds == rowStarts {
+	if colEnds == colStarts {
 		return nil, errors.New("invalid cell reference: no column specified")
 	}
 	if offset >= len(runes) {
 		return nil, errors.New("invalid cell reference: no row specified")
 	}
 	if runes[offset] == '$' {
-		result.colAbsolute = true
+		result.rowAbsolute = true
 		offset++ // consume the '$'
 	}
-	var colStarts = offset
-	var colEnds = offset
+	var rowStarts = offset
+	var rowEnds = offset
 	for offset < len(runes) && isDigit(runes[offset]) {
 		offset += 1
-		colEnds += 1
+		rowEnds += 1
 	}
-	if colEnds == colStarts {
+	if rowEnds == rowStarts {
 		return nil, errors.New("invalid cell reference: no row specified")
 	}
 	if offset < len(runes) {
@@ -649,6 +1367,59 @@ func parseCell(cell string) (result *parseCellResult, err error) {
 	return
 }
 
+// parseNumber converts a number literal written with the locale decimal separator.
+func (p *Parser) parseNumber(raw string) (float64, error) {
+	if p.options.Locale.DecimalSeparator != '.' {
+		raw = strings.ReplaceAll(raw, string(p.options.Locale.DecimalSeparator), ".")
+	}
+	return strconv.ParseFloat(raw, 64)
+}
+
+// parseR1C1 parses an R1C1 style reference lexed by lexer.r1c1Reference.
+func parseR1C1(raw string) (row, col R1C1Index, err error) {
+	var rest = raw
+	var axis = func(letter byte) (index R1C1Index, err error) {
+		if len(rest) == 0 || unicode.ToUpper(rune(rest[0])) != rune(letter) {
+			return R1C1Index{Omitted: true}, nil
+		}
+		rest = rest[1:]
+		var digits = rest
+		if i := strings.IndexFunc(rest, func(r rune) bool { return unicode.ToUpper(r) == 'C' }); i >= 0 && letter == 'R' {
+			digits, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		switch {
+		case digits == "":
+			return R1C1Index{Relative: true}, nil // e.g., R, C
+		case digits[0] == '[':
+			offset, err := strconv.Atoi(digits[1 : len(digits)-1])
+			if err != nil {
+				return index, err
+			}
+			return R1C1Index{Value: offset, Relative: true}, nil
+		default:
+			value, err := strconv.Atoi(digits)
+			if err != nil {
+				return index, err
+			}
+			var limit = MaxRows
+			if letter == 'C' {
+				limit = MaxColumns
+			}
+			if value < 1 || value > limit {
+				return index, fmt.Errorf("%c%d is out of range", letter, value)
+			}
+			return R1C1Index{Value: value - 1}, nil
+		}
+	}
+	if row, err = axis('R'); err != nil {
+		return
+	}
+	col, err = axis('C')
+	return
+}
+
 func colNameToIndex(name string) int {
 	if len(name) == 0 {
 		return -1 // Invalid column name
@@ -658,7 +1429,255 @@ func colNameToIndex(name string) int {
 		if !isASCIILetter(r) {
 			return -1 // Invalid character in column name
 		}
-		index = index*26 + int(r-'A'+1)
+		index = index*26 + int(unicode.ToUpper(r)-'A'+1)
 	}
 	return index - 1 // Convert to zero-based index
 }
+
+// isCallable reports whether the result of node may be invoked like a function.
+func isCallable(node Node) bool {
+	switch node.(type) {
+	case LambdaExpr, LetExpr, CallExpr, FunCallExpr, ParenthesizedExpr:
+		return true
+	default:
+		return false
+	}
+}
+
+// canonicalFunctionName returns the upper case function name without the
+// _xlfn. prefix used for newer functions in OOXML files (e.g., _xlfn.LET).
+func canonicalFunctionName(name string) string {
+	var upper = strings.ToUpper(name)
+	return strings.TrimPrefix(upper, "_XLFN.")
+}
+
+// startsReference reports whether the current token can be the first token of
+// a reference operand.
+func (p *Parser) startsReference() (bool, error) {
+	switch p.token.Type {
+	case Cell, Ident, AbsoluteRow, AbsoluteColumn, ParenOpen, BracketOpen, R1C1Reference:
+		return true, nil
+	case Number: // e.g., the rows 1:1
+		next, err := p.peek()
+		return next != nil && next.Type == Colon, err
+	case String:
+		return isQuotedSheetName(p.token.Raw), nil // e.g., 'My Sheet'!A1
+	default:
+		return false, nil
+	}
+}
+
+// referenceFunctions are the functions that may return a reference, e.g. the
+// INDEX in INDEX(A:A,2) B:B.
+var referenceFunctions = map[string]bool{
+	"CHOOSE":   true,
+	"IF":       true,
+	"INDEX":    true,
+	"INDIRECT": true,
+	"OFFSET":   true,
+	"XLOOKUP":  true,
+}
+
+// isReference reports whether node may evaluate to a reference, so that it
+// can be an operand of the intersection and union operators.
+func isReference(node Node) bool {
+	switch node := node.(type) {
+	case CellExpr, RangeExpr, IdentExpr, SheetRefExpr, Sheet3DRefExpr, ExternalRefExpr, StructuredRefExpr, R1C1Expr:
+		return true
+	case ParenthesizedExpr:
+		return isReference(node.Inner)
+	case BinaryExpr:
+		switch node.Operator.Type {
+		case Intersection, Comma, Semicolon: // the union operator is the argument separator
+			return true
+		}
+		return false
+	case UnaryExpr:
+		return node.Operator.Type == Spill
+	case FunCallExpr:
+		return referenceFunctions[canonicalFunctionName(node.Name.Raw)]
+	default:
+		return false
+	}
+}
+
+// isSpillAnchor reports whether node may be followed by the spilled range
+// operator: a single cell or a name, possibly qualified by a sheet.
+func isSpillAnchor(node Node) bool {
+	switch node := node.(type) {
+	case CellExpr:
+		return node.Row >= 0 && node.Col >= 0
+	case R1C1Expr:
+		return !node.Row.Omitted && !node.Col.Omitted
+	case IdentExpr:
+		return true
+	case SheetRefExpr:
+		return isSpillAnchor(node.Ref)
+	case ExternalRefExpr:
+		return isSpillAnchor(node.Ref)
+	default:
+		return false
+	}
+}
+
+// isSheetQualifiable reports whether node may follow a sheet prefix like Sheet1!.
+func isSheetQualifiable(node Node) bool {
+	switch node := node.(type) {
+	case CellExpr, RangeExpr, IdentExpr, StructuredRefExpr, R1C1Expr:
+		return true
+	case LiteralExpr:
+		return node.Value.Type == EValue // e.g., Sheet1!#REF!
+	default:
+		return false
+	}
+}
+
+// splitExternalName splits an unquoted sheet prefix like C:\dir\[Book.xlsx]Sheet1
+// into its directory, workbook and sheet parts.
+func splitExternalName(name string) (path, workbook, sheet string, ok bool) {
+	var open = strings.IndexByte(name, '[')
+	if open < 0 {
+		return "", "", "", false
+	}
+	var close = strings.IndexByte(name[open:], ']')
+	if close < 0 {
+		return "", "", "", false
+	}
+	close += open
+	return name[:open], name[open+1 : close], name[close+1:], true
+}
+
+// isSheetNameToken reports whether tk can be used as a sheet name in a sheet prefix.
+func isSheetNameToken(tk *Token) bool {
+	switch tk.Type {
+	case Ident, Cell:
+		return true
+	case String:
+		return isQuotedSheetName(tk.Raw)
+	default:
+		return false
+	}
+}
+
+func isQuotedSheetName(raw string) bool {
+	return len(raw) >= 2 && raw[0] == '\''
+}
+
+// unquoteSheetName strips the single quotes around a sheet name and
+// unescapes the doubled apostrophes inside it.
+func unquoteSheetName(raw string) string {
+	if !isQuotedSheetName(raw) {
+		return raw
+	}
+	return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'")
+}
+
+var structuredItems = map[string]StructuredItem{
+	"#ALL":      ItemAll,
+	"#DATA":     ItemData,
+	"#HEADERS":  ItemHeaders,
+	"#TOTALS":   ItemTotals,
+	"#THIS ROW": ItemThisRow,
+}
+
+// parseStructuredSpecifier parses the bracketed part of a structured reference, e.g.
+// [Col], [#Headers], [@Col], [@[Col A]:[Col C]] or [[#Headers],[#Data],[Col A]:[Col C]].
+func parseStructuredSpecifier(raw string) (items []StructuredItem, firstColumn, lastColumn string, err error) {
+	var src = []rune(raw[1 : len(raw)-1])
+	if len(src) > 0 && src[0] == '@' {
+		items = append(items, ItemThisRow)
+		src = src[1:]
+		if len(src) == 0 || src[0] != '[' {
+			firstColumn = unescapeColumnName(src) // e.g., [@Col]
+			return
+		}
+	}
+	if len(src) == 0 {
+		return
+	}
+	if src[0] == '#' {
+		var item, ok = structuredItems[strings.ToUpper(string(src))]
+		if !ok {
+			return nil, "", "", fmt.Errorf("unknown item specifier %s", string(src))
+		}
+		return append(items, item), "", "", nil
+	}
+	if src[0] != '[' {
+		return items, unescapeColumnName(src), "", nil // e.g., [Col]
+	}
+
+	var offset = 0
+	var skipSpaces = func() {
+		for offset < len(src) && src[offset] == ' ' {
+			offset++
+		}
+	}
+	var part = func() ([]rune, error) {
+		if offset >= len(src) || src[offset] != '[' {
+			return nil, errors.New("expected '['")
+		}
+		offset++ // consume the '['
+		var start = offset
+		for offset < len(src) && src[offset] != ']' {
+			if src[offset] == '\'' {
+				offset++ // skip the escaped character
+			}
+			offset++
+		}
+		if offset >= len(src) {
+			return nil, errors.New("unclosed '['")
+		}
+		offset++ // consume the ']'
+		return src[start : offset-1], nil
+	}
+	for offset < len(src) {
+		skipSpaces()
+		first, err := part()
+		if err != nil {
+			return nil, "", "", err
+		}
+		if len(first) > 0 && first[0] == '#' {
+			var item, ok = structuredItems[strings.ToUpper(string(first))]
+			if !ok {
+				return nil, "", "", fmt.Errorf("unknown item specifier %s", string(first))
+			}
+			items = append(items, item)
+		} else {
+			if firstColumn != "" {
+				return nil, "", "", errors.New("unexpected column after column specifier")
+			}
+			firstColumn = unescapeColumnName(first)
+			skipSpaces()
+			if offset < len(src) && src[offset] == ':' {
+				offset++ // consume the ':'
+				skipSpaces()
+				last, err := part()
+				if err != nil {
+					return nil, "", "", err
+				}
+				lastColumn = unescapeColumnName(last)
+			}
+		}
+		skipSpaces()
+		if offset < len(src) {
+			if src[offset] != ',' {
+				return nil, "", "", fmt.Errorf("unexpected character %c", src[offset])
+			}
+			offset++ // consume the ','
+		}
+	}
+	return
+}
+
+// unescapeColumnName removes the apostrophes escaping special characters
+// (e.g., '[, '], '# and a doubled apostrophe) from a structured reference column name.
+func unescapeColumnName(name []rune) string {
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\'' && i+1 < len(name) {
+			i++
+		}
+		sb.WriteRune(name[i])
+	}
+	return sb.String()
+}
@@ -33,9 +33,70 @@ func TestParse(t *testing.T) {
 		{"=TRUE", "LiteralExpr(Value: TRUE)"},
 		{"=SUM()", "FunCallExpr(Name: SUM, Arguments: [])"},
 		{"=SUM(1,2)", "FunCallExpr(Name: SUM, Arguments: [LiteralExpr(Value: 1), LiteralExpr(Value: 2)])"},
+		{"=LOG10(A1)+ATAN2", "BinaryExpr(Left: FunCallExpr(Name: LOG10, Arguments: [CellExpr(A1)]), Operator: +, Right: IdentExpr(Name: ATAN2))"},
+		{"=XFD1048576+XFE1+A1048577+A0", "BinaryExpr(Left: BinaryExpr(Left: BinaryExpr(Left: CellExpr(XFD1048576), Operator: +, Right: IdentExpr(Name: XFE1)), Operator: +, Right: IdentExpr(Name: A1048577)), Operator: +, Right: IdentExpr(Name: A0))"},
 		{"=1 + 2 - 3", "BinaryExpr(Left: BinaryExpr(Left: LiteralExpr(Value: 1), Operator: +, Right: LiteralExpr(Value: 2)), Operator: -, Right: LiteralExpr(Value: 3))"},
 		{"=1+(2-3)", "BinaryExpr(Left: LiteralExpr(Value: 1), Operator: +, Right: ParenthesizedExpr(Inner: BinaryExpr(Left: LiteralExpr(Value: 2), Operator: -, Right: LiteralExpr(Value: 3))))"},
 		{"=1+2*3", "BinaryExpr(Left: LiteralExpr(Value: 1), Operator: +, Right: BinaryExpr(Left: LiteralExpr(Value: 2), Operator: *, Right: LiteralExpr(Value: 3)))"},
+		{"=1.5E+10*2e-3-.5", "BinaryExpr(Left: BinaryExpr(Left: LiteralExpr(Value: 1.5E+10), Operator: *, Right: LiteralExpr(Value: 2e-3)), Operator: -, Right: LiteralExpr(Value: .5))"},
+		{"=Sheet1!A1", "SheetRefExpr(Sheet: Sheet1, Ref: CellExpr(A1))"},
+		{"='My Sheet'!B2:C9", "SheetRefExpr(Sheet: My Sheet, Ref: RangeExpr(CellExpr(B2):CellExpr(C9)))"},
+		{"='Bob''s Data'!$A:$A", "SheetRefExpr(Sheet: Bob's Data, Ref: RangeExpr(CellExpr($A):CellExpr($A)))"},
+		{"=Data!1:3", "SheetRefExpr(Sheet: Data, Ref: RangeExpr(CellExpr(1):CellExpr(3)))"},
+		{"=Data!Total", "SheetRefExpr(Sheet: Data, Ref: IdentExpr(Name: Total))"},
+		{"=Sheet1!#REF!", "SheetRefExpr(Sheet: Sheet1, Ref: LiteralExpr(Value: #REF!))"},
+		{"=SUM(Jan:Dec!B2)", "FunCallExpr(Name: SUM, Arguments: [Sheet3DRefExpr(FirstSheet: Jan, LastSheet: Dec, Ref: CellExpr(B2))])"},
+		{"=Sheet1:Sheet5!A1:B3", "Sheet3DRefExpr(FirstSheet: Sheet1, LastSheet: Sheet5, Ref: RangeExpr(CellExpr(A1):CellExpr(B3)))"},
+		{"='Jan 2024:Dec 2024'!C4", "Sheet3DRefExpr(FirstSheet: Jan 2024, LastSheet: Dec 2024, Ref: CellExpr(C4))"},
+		{"=A1:B2+Jan:Dec!B2", "BinaryExpr(Left: RangeExpr(CellExpr(A1):CellExpr(B2)), Operator: +, Right: Sheet3DRefExpr(FirstSheet: Jan, LastSheet: Dec, Ref: CellExpr(B2)))"},
+		{"=[Book.xlsx]Sheet1!A1", "ExternalRefExpr(Workbook: Book.xlsx, Sheet: Sheet1, Ref: CellExpr(A1))"},
+		{"=[1]Sheet1!$A$1:B2", "ExternalRefExpr(Workbook: 1, Sheet: Sheet1, Ref: RangeExpr(CellExpr($A$1):CellExpr(B2)))"},
+		{"=[1]!Rate", "ExternalRefExpr(Workbook: 1, Sheet: , Ref: IdentExpr(Name: Rate))"},
+		{"=[2]Jan:Dec!B2", "ExternalRefExpr(Workbook: 2, Sheet: Jan:Dec, Ref: CellExpr(B2))"},
+		{`='C:\dir\[Book 1.xlsx]My Sheet'!A1`, `ExternalRefExpr(Workbook: C:\dir\Book 1.xlsx, Sheet: My Sheet, Ref: CellExpr(A1))`},
+		{"='[Book.xlsx]Sheet1'!A1*2", "BinaryExpr(Left: ExternalRefExpr(Workbook: Book.xlsx, Sheet: Sheet1, Ref: CellExpr(A1)), Operator: *, Right: LiteralExpr(Value: 2))"},
+		{"=Table1[Column]", "StructuredRefExpr(Table: Table1, Items: [], Columns: Column)"},
+		{"=Sales[]", "StructuredRefExpr(Table: Sales, Items: [], Columns: )"},
+		{"=Sales[#Totals]", "StructuredRefExpr(Table: Sales, Items: [#Totals], Columns: )"},
+		{"=Table1[[#Headers],[Col A]:[Col C]]", "StructuredRefExpr(Table: Table1, Items: [#Headers], Columns: Col A:Col C)"},
+		{"=Table1[[#Headers], [#Data], [Amount]]", "StructuredRefExpr(Table: Table1, Items: [#Headers, #Data], Columns: Amount)"},
+		{"=Table1[[#This Row],[Qty]]*[@Price]", "BinaryExpr(Left: StructuredRefExpr(Table: Table1, Items: [#This Row], Columns: Qty), Operator: *, Right: StructuredRefExpr(Table: , Items: [#This Row], Columns: Price))"},
+		{"=[@[Unit Price]]", "StructuredRefExpr(Table: , Items: [#This Row], Columns: Unit Price)"},
+		{"=[@]", "StructuredRefExpr(Table: , Items: [#This Row], Columns: )"},
+		{"=SUM([Qty])", "FunCallExpr(Name: SUM, Arguments: [StructuredRefExpr(Table: , Items: [], Columns: Qty)])"},
+		{"=Table1[[Price '[USD']]:[Total '#]]", "StructuredRefExpr(Table: Table1, Items: [], Columns: Price [USD]:Total #)"},
+		{"=Table1[it''s]", "StructuredRefExpr(Table: Table1, Items: [], Columns: it's)"},
+		{"=SUM(A2#)", "FunCallExpr(Name: SUM, Arguments: [UnaryExpr(Operator: #, Operand: CellExpr(A2))])"},
+		{"=Sheet2!B4#", "UnaryExpr(Operator: #, Operand: SheetRefExpr(Sheet: Sheet2, Ref: CellExpr(B4)))"},
+		{"=$A$2#*2", "BinaryExpr(Left: UnaryExpr(Operator: #, Operand: CellExpr($A$2)), Operator: *, Right: LiteralExpr(Value: 2))"},
+		{"=@Results#", "UnaryExpr(Operator: @, Operand: UnaryExpr(Operator: #, Operand: IdentExpr(Name: Results)))"},
+		{"=IF(A1,#SPILL!,#GETTING_DATA)", "FunCallExpr(Name: IF, Arguments: [CellExpr(A1), LiteralExpr(Value: #SPILL!), LiteralExpr(Value: #GETTING_DATA)])"},
+		{"=IF(A1,#N/A)", "FunCallExpr(Name: IF, Arguments: [CellExpr(A1), LiteralExpr(Value: #N/A)])"},
+		{"=A1:C5 B2:D8", "BinaryExpr(Left: RangeExpr(CellExpr(A1):CellExpr(C5)), Operator:  , Right: RangeExpr(CellExpr(B2):CellExpr(D8)))"},
+		{"=Sales Q1", "BinaryExpr(Left: IdentExpr(Name: Sales), Operator:  , Right: CellExpr(Q1))"},
+		{"=SUM(A:A 1:1  B:B) * 2 ", "BinaryExpr(Left: FunCallExpr(Name: SUM, Arguments: [BinaryExpr(Left: BinaryExpr(Left: RangeExpr(CellExpr(A):CellExpr(A)), Operator:  , Right: RangeExpr(CellExpr(1):CellExpr(1))), Operator:   , Right: RangeExpr(CellExpr(B):CellExpr(B)))]), Operator: *, Right: LiteralExpr(Value: 2))"},
+		{"=@Jan 'Feb Data'!A1:B2", "UnaryExpr(Operator: @, Operand: BinaryExpr(Left: IdentExpr(Name: Jan), Operator:  , Right: SheetRefExpr(Sheet: Feb Data, Ref: RangeExpr(CellExpr(A1):CellExpr(B2)))))"},
+		{"=A1:B2 (B1:C2)", "BinaryExpr(Left: RangeExpr(CellExpr(A1):CellExpr(B2)), Operator:  , Right: ParenthesizedExpr(Inner: RangeExpr(CellExpr(B1):CellExpr(C2))))"},
+		{"=A1 (B1)", "BinaryExpr(Left: CellExpr(A1), Operator:  , Right: ParenthesizedExpr(Inner: CellExpr(B1)))"},
+		{"=Name (B1)", "BinaryExpr(Left: IdentExpr(Name: Name), Operator:  , Right: ParenthesizedExpr(Inner: CellExpr(B1)))"},
+		{"=(A1:B2) (B1:C3)", "BinaryExpr(Left: ParenthesizedExpr(Inner: RangeExpr(CellExpr(A1):CellExpr(B2))), Operator:  , Right: ParenthesizedExpr(Inner: RangeExpr(CellExpr(B1):CellExpr(C3))))"},
+		{"=INDEX(A:B,2,0) B:B", "BinaryExpr(Left: FunCallExpr(Name: INDEX, Arguments: [RangeExpr(CellExpr(A):CellExpr(B)), LiteralExpr(Value: 2), LiteralExpr(Value: 0)]), Operator:  , Right: RangeExpr(CellExpr(B):CellExpr(B)))"},
+		{"= A1 + B1\n", "BinaryExpr(Left: CellExpr(A1), Operator: +, Right: CellExpr(B1))"},
+		{"=SUM((A1:A3,C1:C3))", "FunCallExpr(Name: SUM, Arguments: [ParenthesizedExpr(Inner: BinaryExpr(Left: RangeExpr(CellExpr(A1):CellExpr(A3)), Operator: ,, Right: RangeExpr(CellExpr(C1):CellExpr(C3))))])"},
+		{"=LARGE((A1,B5,C9),2)", "FunCallExpr(Name: LARGE, Arguments: [ParenthesizedExpr(Inner: BinaryExpr(Left: BinaryExpr(Left: CellExpr(A1), Operator: ,, Right: CellExpr(B5)), Operator: ,, Right: CellExpr(C9))), LiteralExpr(Value: 2)])"},
+		{"=(Sheet1!A1, A2:B3 B3)", "ParenthesizedExpr(Inner: BinaryExpr(Left: SheetRefExpr(Sheet: Sheet1, Ref: CellExpr(A1)), Operator: ,, Right: BinaryExpr(Left: RangeExpr(CellExpr(A2):CellExpr(B3)), Operator:  , Right: CellExpr(B3))))"},
+		{"=LET(x, A1*2, x+1)", "LetExpr(Bindings: [x = BinaryExpr(Left: CellExpr(A1), Operator: *, Right: LiteralExpr(Value: 2))], Body: BinaryExpr(Left: IdentExpr(Name: x), Operator: +, Right: LiteralExpr(Value: 1)))"},
+		{"=LAMBDA(a,b,a+b)(1,2)", "CallExpr(Callee: LambdaExpr(Params: [a, b], Body: BinaryExpr(Left: IdentExpr(Name: a), Operator: +, Right: IdentExpr(Name: b))), Arguments: [LiteralExpr(Value: 1), LiteralExpr(Value: 2)])"},
+		{"=LET(f, LAMBDA(x, x*2), f(3))", "LetExpr(Bindings: [f = LambdaExpr(Params: [x], Body: BinaryExpr(Left: IdentExpr(Name: x), Operator: *, Right: LiteralExpr(Value: 2)))], Body: CallExpr(Callee: IdentExpr(Name: f), Arguments: [LiteralExpr(Value: 3)]))"},
+		{"=_xlfn.LAMBDA(_xlpm.n, _xlpm.n)(1)(2)", "CallExpr(Callee: CallExpr(Callee: LambdaExpr(Params: [_xlpm.n], Body: IdentExpr(Name: _xlpm.n)), Arguments: [LiteralExpr(Value: 1)]), Arguments: [LiteralExpr(Value: 2)])"},
+		{"=LAMBDA(Rate)", "LambdaExpr(Params: [], Body: IdentExpr(Name: Rate))"},
+		{"=IF(A1,,0)", "FunCallExpr(Name: IF, Arguments: [CellExpr(A1), EmptyArgExpr(), LiteralExpr(Value: 0)])"},
+		{"=VLOOKUP(A1,B:C,2,)", "FunCallExpr(Name: VLOOKUP, Arguments: [CellExpr(A1), RangeExpr(CellExpr(B):CellExpr(C)), LiteralExpr(Value: 2), EmptyArgExpr()])"},
+		{"=SUM(,)", "FunCallExpr(Name: SUM, Arguments: [EmptyArgExpr(), EmptyArgExpr()])"},
+		{"=F( ,1)", "FunCallExpr(Name: F, Arguments: [EmptyArgExpr(), LiteralExpr(Value: 1)])"},
+		{"=LAMBDA(a,b,a)(1,)", "CallExpr(Callee: LambdaExpr(Params: [a, b], Body: IdentExpr(Name: a)), Arguments: [LiteralExpr(Value: 1), EmptyArgExpr()])"},
+		{"=- 2", "UnaryExpr(Operator: -, Operand: LiteralExpr(Value: 2))"},
+		{"=SUM(Sheet1!A1, '工作表 2'!B2) + A1", "BinaryExpr(Left: FunCallExpr(Name: SUM, Arguments: [SheetRefExpr(Sheet: Sheet1, Ref: CellExpr(A1)), SheetRefExpr(Sheet: 工作表 2, Ref: CellExpr(B2))]), Operator: +, Right: CellExpr(A1))"},
 	}
 	for _, test := range tests {
 		p := NewParser(test.src)
@@ -47,6 +108,216 @@ func TestParse(t *testing.T) {
 		if node.String() != test.expected {
 			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, node.String())
 		}
+		if got := Source(node); got != test.src {
+			t.Errorf("For input '%s', expected source '%s', got '%s'", test.src, test.src, got)
+		}
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		src      string
+		raw      string
+		expected float64
+	}{
+		{"=123", "123", 123},
+		{"=0.25", "0.25", 0.25},
+		{"=.5", ".5", 0.5},
+		{"=1.5E+10", "1.5E+10", 1.5e10},
+		{"=2e-3", "2e-3", 0.002},
+		{"=-1E3", "-1E3", -1000},
+		{"=+.5", "+.5", 0.5},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		lit, ok := node.(LiteralExpr)
+		if !ok {
+			t.Errorf("For input '%s', expected LiteralExpr, got %T", test.src, node)
+			continue
+		}
+		if lit.Value.Raw != test.raw || lit.Number != test.expected {
+			t.Errorf("For input '%s', expected %s (%v), got %s (%v)", test.src, test.raw, test.expected, lit.Value.Raw, lit.Number)
+		}
+	}
+	for _, src := range []string{"=1.", "=1E999", "=1.2.3"} {
+		if _, err := NewParser(src).Parse(); err == nil {
+			t.Errorf("Expected parse error for '%s'", src)
+		}
+	}
+}
+
+func TestParseR1C1(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"=R1C1", "R1C1Expr(R1C1)"},
+		{"=R[-1]C[2]", "R1C1Expr(R[-1]C[2])"},
+		{"=rc", "R1C1Expr(RC)"},
+		{"=R2", "R1C1Expr(R2)"},
+		{"=SUM(C[3]:C[5])", "FunCallExpr(Name: SUM, Arguments: [RangeExpr(R1C1Expr(C[3]):R1C1Expr(C[5]))])"},
+		{"=ROUND(Sheet1!R1C[-1], 2)", "FunCallExpr(Name: ROUND, Arguments: [SheetRefExpr(Sheet: Sheet1, Ref: R1C1Expr(R1C[-1])), LiteralExpr(Value: 2)])"},
+		{"=R1C1 (R2C2)", "BinaryExpr(Left: R1C1Expr(R1C1), Operator:  , Right: ParenthesizedExpr(Inner: R1C1Expr(R2C2)))"},
+		{"=COUNT(R2C2:R[3]C) + A1", "BinaryExpr(Left: FunCallExpr(Name: COUNT, Arguments: [RangeExpr(R1C1Expr(R2C2):R1C1Expr(R[3]C))]), Operator: +, Right: IdentExpr(Name: A1))"},
+	}
+	for _, test := range tests {
+		node, err := NewParserWithOptions(test.src, ParseOptions{R1C1: true}).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		if node.String() != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, node.String())
+		}
+	}
+
+	node, err := NewParserWithOptions("=R[-2]C3", ParseOptions{R1C1: true}).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var ref = node.(R1C1Expr)
+	if ref.Row != (R1C1Index{Value: -2, Relative: true}) || ref.Col != (R1C1Index{Value: 2}) {
+		t.Errorf("Unexpected R1C1 indexes: %+v %+v", ref.Row, ref.Col)
+	}
+	for _, src := range []string{"=R1048576C16384", "=R1C1:C16384"} {
+		if _, err := NewParserWithOptions(src, ParseOptions{R1C1: true}).Parse(); err != nil {
+			t.Errorf("Parse error for '%s': %v", src, err)
+		}
+	}
+	for _, src := range []string{"=R0C1", "=R1C0", "=R1048577C1", "=C16385"} {
+		if _, err := NewParserWithOptions(src, ParseOptions{R1C1: true}).Parse(); err == nil {
+			t.Errorf("Expected parse error for '%s'", src)
+		}
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	node, err := NewParser("=LET(x, 1, y, x+Rate, LAMBDA(x, x+y)(y))").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var let = node.(LetExpr)
+	var x, y = let.Bindings[0].Name, let.Bindings[1].Name
+	if ident := let.Bindings[1].Value.(BinaryExpr).Left.(IdentExpr); ident.Binding != x {
+		t.Errorf("Expected x in the value of y to refer to the LET name")
+	}
+	if ident := let.Bindings[1].Value.(BinaryExpr).Right.(IdentExpr); ident.Binding != nil {
+		t.Errorf("Expected Rate to be a defined name, got binding %v", ident.Binding)
+	}
+	var call = let.Body.(CallExpr)
+	var lambda = call.Callee.(LambdaExpr)
+	var body = lambda.Body.(BinaryExpr)
+	if ident := body.Left.(IdentExpr); ident.Binding != lambda.Params[0] {
+		t.Errorf("Expected x in the LAMBDA body to refer to the parameter")
+	}
+	if ident := body.Right.(IdentExpr); ident.Binding != y {
+		t.Errorf("Expected y in the LAMBDA body to refer to the LET name")
+	}
+	if ident := call.Arguments[0].(IdentExpr); ident.Binding != y {
+		t.Errorf("Expected y in the call arguments to refer to the LET name")
+	}
+
+	node, err = NewParser("=LET(x, x, x)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	let = node.(LetExpr)
+	if let.Bindings[0].Value.(IdentExpr).Binding != nil {
+		t.Errorf("Expected a LET name not to be visible in its own value")
+	}
+	if let.Body.(IdentExpr).Binding != let.Bindings[0].Name {
+		t.Errorf("Expected x in the calculation to refer to the LET name")
+	}
+}
+
+func TestEmptyArgPosition(t *testing.T) {
+	node, err := NewParser("=IF(A1,,0)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var arg = node.(FunCallExpr).Arguments[1]
+	if arg.Start() != (Pos{Line: 1, Column: 8}) || arg.End() != (Pos{Line: 1, Column: 8}) {
+		t.Errorf("Expected the omitted argument at (1,8), got %s-%s", arg.Start(), arg.End())
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []string{
+		"=Sheet1!",
+		"=Sheet1!SUM(A1)",
+		"=Sheet1!Sheet2!A1",
+		"=Jan:Dec!",
+		"=Jan:Dec!Jan:Dec!A1",
+		"=[Book.xlsx",
+		"=[Book.xlsx]Sheet1",
+		"=[Book.xlsx]Sheet1!",
+		"=Table1[#Everything]",
+		"=Table1[[Col A],[Col B]]",
+		"=Table1[[Col A]",
+		"=A1 #",
+		"=1#",
+		"=A1:B2#",
+		"=Sheet1!A1:B2#",
+		"=(A1,",
+		"=(A1,B1",
+		"=A1,B1",
+		"=(1,2)",
+		"=(\"a\",B1)",
+		"=(A1,B1+1)",
+		"=SUM(1 \"a\")",
+		"=2 3",
+		"={1 2}",
+		"=1 2:3",
+		"=A1 1",
+		"=A1:B2 SUM(C1)",
+		"=LET(x, 1)",
+		"=SUM(,",
+		"=SUM(1,",
+		"=LET(x, 1, x",
+		"=LAMBDA(a, a, a)",
+		"=LAMBDA()",
+		"='Sheet1'!\"text\"",
+	}
+	for _, src := range tests {
+		if _, err := NewParser(src).Parse(); err == nil {
+			t.Errorf("Expected parse error for '%s'", src)
+		}
+	}
+}
+
+func TestExternalRefExpr(t *testing.T) {
+	tests := []struct {
+		src      string
+		path     string
+		workbook string
+		index    int
+		ok       bool
+	}{
+		{"=[1]Sheet1!A1", "", "1", 1, true},
+		{"=[Book.xlsx]Sheet1!A1", "", "Book.xlsx", 0, false},
+		{`='C:\dir\[Book.xlsx]Sheet1'!A1`, `C:\dir\`, "Book.xlsx", 0, false},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		ref, ok := node.(ExternalRefExpr)
+		if !ok {
+			t.Errorf("For input '%s', expected ExternalRefExpr, got %T", test.src, node)
+			continue
+		}
+		if ref.Path != test.path || ref.Workbook != test.workbook {
+			t.Errorf("For input '%s', expected path '%s' and workbook '%s', got '%s' and '%s'", test.src, test.path, test.workbook, ref.Path, ref.Workbook)
+		}
+		if index, ok := ref.WorkbookIndex(); index != test.index || ok != test.ok {
+			t.Errorf("For input '%s', expected index %d (%v), got %d (%v)", test.src, test.index, test.ok, index, ok)
+		}
 	}
 }
 
@@ -62,6 +333,8 @@ func Test_colNameToIndex(t *testing.T) {
 		{"AB", 27},
 		{"AZ", 51},
 		{"BA", 52},
+		{"ab", 27},
+		{"XFD", 16383},
 	}
 	for _, test := range tests {
 		result := colNameToIndex(test.colName)
@@ -70,3 +343,26 @@ func Test_colNameToIndex(t *testing.T) {
 		}
 	}
 }
+
+func Test_parseCell(t *testing.T) {
+	tests := []struct {
+		cell     string
+		expected parseCellResult
+	}{
+		{"A1", parseCellResult{row: 0, col: 0}},
+		{"B3", parseCellResult{row: 2, col: 1}},
+		{"$C$10", parseCellResult{row: 9, col: 2, rowAbsolute: true, colAbsolute: true}},
+		{"$AA5", parseCellResult{row: 4, col: 26, colAbsolute: true}},
+		{"d$4", parseCellResult{row: 3, col: 3, rowAbsolute: true}},
+	}
+	for _, test := range tests {
+		result, err := parseCell(test.cell)
+		if err != nil {
+			t.Errorf("parseCell(%s) error: %v", test.cell, err)
+			continue
+		}
+		if *result != test.expected {
+			t.Errorf("parseCell(%s) = %+v; want %+v", test.cell, *result, test.expected)
+		}
+	}
+}
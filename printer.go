@@ -0,0 +1,313 @@
+package excelformulaparser
+
+import (
+	"strings"
+)
+
+// Printer renders an AST as formula text.
+type Printer struct {
+	Locale         *Locale // The syntax of the output, nil for DefaultLocale
+	PreserveParens bool    // Keep every ParenthesizedExpr instead of only the necessary parentheses
+}
+
+// Format returns the formula text of node with a leading '=', e.g. =A1+B1.
+func Format(node Node) string {
+	return (&Printer{}).Format(node)
+}
+
+// Format returns the formula text of node with a leading '='.
+// Function names are translated from English to the names of p.Locale, and
+// the separators of p.Locale are used for lists and numbers.
+//
+// Parentheses are only printed where the operator precedence requires them,
+// so =((A1+B1))*2 becomes =(A1+B1)*2 and =(A1*2)+1 becomes =A1*2+1, unless
+// p.PreserveParens is set.
+func (p *Printer) Format(node Node) string {
+	var locale = p.Locale
+	if locale == nil {
+		locale = DefaultLocale
+	}
+	var w = &printer{locale: locale, names: locale.localNames(), booleans: locale.localBooleans(), preserveParens: p.PreserveParens}
+	w.sb.WriteByte('=')
+	w.expr(node, precComparison)
+	return w.sb.String()
+}
+
+// Operator precedence, from the loosest to the tightest binding, following
+// the descent of Parser.union to Parser.primary.
+const (
+	precUnion = iota + 1
+	precComparison
+	precConcat
+	precAddition
+	precMultiplication
+	precExponentiation
+	precPercent
+	precNegation
+	precImplicitIntersection
+	precIntersection
+	precSpill
+	precRange
+	precPrimary
+)
+
+type printer struct {
+	sb             strings.Builder
+	locale         *Locale
+	names          map[string]string // English function names to localized names
+	booleans       map[string]string // TRUE and FALSE to their localized names
+	preserveParens bool
+}
+
+// expr prints node, parenthesized if it binds looser than min.
+func (w *printer) expr(node Node, min int) {
+	node = w.unparen(node)
+	if precedence(node) < min {
+		w.sb.WriteByte('(')
+		w.expr(node, precUnion)
+		w.sb.WriteByte(')')
+		return
+	}
+	w.node(node)
+}
+
+// unparen removes the parentheses around node unless they are preserved.
+func (w *printer) unparen(node Node) Node {
+	for !w.preserveParens {
+		paren, ok := node.(ParenthesizedExpr)
+		if !ok {
+			break
+		}
+		node = paren.Inner
+	}
+	return node
+}
+
+func (w *printer) node(node Node) {
+	switch n := node.(type) {
+	case LiteralExpr:
+		w.literal(n)
+	case CellExpr:
+		w.sb.WriteString(n.Ident.Raw)
+	case R1C1Expr:
+		w.sb.WriteString(n.Ident.Raw)
+	case IdentExpr:
+		w.sb.WriteString(n.Name.Raw)
+	case RangeExpr:
+		w.expr(n.Begin, precPrimary)
+		for _, end := range n.Ends {
+			w.sb.WriteByte(':')
+			w.expr(end, precPrimary)
+		}
+	case SheetRefExpr:
+		w.sb.WriteString(n.SheetToken.Raw)
+		w.sb.WriteByte('!')
+		w.expr(n.Ref, precRange)
+	case Sheet3DRefExpr:
+		w.sb.WriteString(n.FirstSheetToken.Raw)
+		if n.LastSheetToken != nil {
+			w.sb.WriteByte(':')
+			w.sb.WriteString(n.LastSheetToken.Raw)
+		}
+		w.sb.WriteByte('!')
+		w.expr(n.Ref, precRange)
+	case ExternalRefExpr:
+		for _, token := range n.Prefix {
+			w.sb.WriteString(token.Raw)
+		}
+		w.sb.WriteByte('!')
+		w.expr(n.Ref, precRange)
+	case StructuredRefExpr:
+		if n.Table != nil {
+			w.sb.WriteString(n.Table.Raw)
+		}
+		w.sb.WriteString(n.Specifier.Raw)
+	case BinaryExpr:
+		var prec = binaryPrecedence(n.Operator)
+		w.expr(n.Left, prec) // binary operators are left associative
+		w.operator(n.Operator)
+		w.expr(n.Right, prec+1)
+	case UnaryExpr:
+		var prec = operandPrecedence(n.Operator)
+		if isPostfixOperator(n.Operator) {
+			w.expr(n.Operand, prec)
+			w.sb.WriteString(n.Operator.Raw)
+		} else {
+			w.sb.WriteString(n.Operator.Raw)
+			w.expr(n.Operand, prec)
+		}
+	case ParenthesizedExpr:
+		w.sb.WriteByte('(')
+		w.expr(n.Inner, precUnion)
+		w.sb.WriteByte(')')
+	case FunCallExpr:
+		w.functionName(n.Name)
+		w.arguments(n.Arguments)
+	case CallExpr:
+		var callee = w.unparen(n.Callee)
+		if ident, ok := callee.(IdentExpr); isCallable(callee) || ok && ident.Binding != nil {
+			w.node(callee) // e.g., LAMBDA(x,x)(1), or the f(3) in LET(f,LAMBDA(x,x*2),f(3))
+		} else {
+			// e.g., the (f)(1) of a LAMBDA bound to a defined name, which is not f(1)
+			w.sb.WriteByte('(')
+			w.expr(callee, precUnion)
+			w.sb.WriteByte(')')
+		}
+		w.arguments(n.Arguments)
+	case LetExpr:
+		w.functionName(n.Name)
+		w.sb.WriteByte('(')
+		for _, binding := range n.Bindings {
+			w.sb.WriteString(binding.Name.Raw)
+			w.sb.WriteRune(w.locale.ArgSeparator)
+			w.expr(binding.Value, precComparison)
+			w.sb.WriteRune(w.locale.ArgSeparator)
+		}
+		w.expr(n.Body, precComparison)
+		w.sb.WriteByte(')')
+	case LambdaExpr:
+		w.functionName(n.Name)
+		w.sb.WriteByte('(')
+		for _, param := range n.Params {
+			w.sb.WriteString(param.Raw)
+			w.sb.WriteRune(w.locale.ArgSeparator)
+		}
+		w.expr(n.Body, precComparison)
+		w.sb.WriteByte(')')
+	case EmptyArgExpr:
+		// an omitted argument has no text
+	case ArrayExpr:
+		w.sb.WriteByte('{')
+		for i, row := range n.Elements {
+			if i > 0 {
+				w.sb.WriteRune(w.locale.ArrayRowSeparator)
+			}
+			for j, element := range row {
+				if j > 0 {
+					w.sb.WriteRune(w.locale.ArrayColumnSeparator)
+				}
+				w.expr(element, precComparison)
+			}
+		}
+		w.sb.WriteByte('}')
+	}
+}
+
+func (w *printer) literal(l LiteralExpr) {
+	if local, ok := w.booleans[strings.ToUpper(l.Value.Raw)]; l.Value.Type == BoolLiteral && ok {
+		w.sb.WriteString(local)
+		return
+	}
+	if l.Value.Type != Number {
+		w.sb.WriteString(l.Value.Raw)
+		return
+	}
+	// A number has at most one '.' or ',', which is the decimal separator of
+	// the source locale.
+	for _, ch := range l.Value.Raw {
+		if ch == '.' || ch == ',' {
+			ch = w.locale.DecimalSeparator
+		}
+		w.sb.WriteRune(ch)
+	}
+}
+
+func (w *printer) operator(op *Token) {
+	switch op.Type {
+	case Intersection:
+		w.sb.WriteByte(' ')
+	case Comma, Semicolon, Backslash, Period:
+		// the union operator is the argument separator, e.g., (A1,B1)
+		w.sb.WriteRune(w.locale.ArgSeparator)
+	default:
+		w.sb.WriteString(op.Raw)
+	}
+}
+
+func (w *printer) functionName(name *Token) {
+	if local, ok := w.names[strings.ToUpper(name.Raw)]; ok {
+		w.sb.WriteString(local)
+		return
+	}
+	w.sb.WriteString(name.Raw)
+}
+
+func (w *printer) arguments(arguments []Node) {
+	w.sb.WriteByte('(')
+	for i, arg := range arguments {
+		if i > 0 {
+			w.sb.WriteRune(w.locale.ArgSeparator)
+		}
+		w.expr(arg, precComparison)
+	}
+	w.sb.WriteByte(')')
+}
+
+// isPostfixOperator reports whether a unary operator follows its operand (e.g., 5% or A1#).
+func isPostfixOperator(op *Token) bool {
+	return op.Type == Percent || op.Type == Spill
+}
+
+// precedence returns how tightly node binds to its operands.
+func precedence(node Node) int {
+	switch n := node.(type) {
+	case BinaryExpr:
+		return binaryPrecedence(n.Operator)
+	case UnaryExpr:
+		switch n.Operator.Type {
+		case Percent:
+			return precPercent
+		case Spill:
+			return precSpill
+		case ImplicitIntersection:
+			return precImplicitIntersection
+		default:
+			return precNegation
+		}
+	case RangeExpr:
+		return precRange
+	case ArrayExpr:
+		return precNegation // array constants are not references, e.g., @{1,2} is invalid
+	case LiteralExpr:
+		if raw := n.Value.Raw; n.Value.Type == Number && (raw[0] == '-' || raw[0] == '+') {
+			return precNegation // a number with its sign, e.g., -1
+		}
+	}
+	return precPrimary
+}
+
+func binaryPrecedence(op *Token) int {
+	switch op.Type {
+	case Comma, Semicolon, Backslash, Period:
+		return precUnion
+	case Equal, NotEqual, LessThan, GreaterThan, LessThanOrEqual, GreaterThanOrEqual:
+		return precComparison
+	case Concat:
+		return precConcat
+	case Plus, Minus:
+		return precAddition
+	case Multiply, Divide:
+		return precMultiplication
+	case Exponentiation:
+		return precExponentiation
+	case Intersection:
+		return precIntersection
+	default:
+		return precPrimary
+	}
+}
+
+// operandPrecedence returns the loosest binding operand a unary operator takes
+// without parentheses.
+func operandPrecedence(op *Token) int {
+	switch op.Type {
+	case Percent:
+		return precPercent // e.g., 5%%
+	case Spill:
+		return precRange // e.g., A1:B2#
+	case ImplicitIntersection:
+		return precIntersection // e.g., @A1:B2 C1:D2
+	default:
+		return precNegation // e.g., --A1
+	}
+}
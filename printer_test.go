@@ -0,0 +1,117 @@
+package excelformulaparser
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"=A1+B1", "=A1+B1"},
+		{"= A1 + B1", "=A1+B1"},
+		{"A1*2", "=A1*2"},
+		{"=(A1+B1)*2", "=(A1+B1)*2"},
+		{"=((A1+B1))*2", "=(A1+B1)*2"},
+		{"=(A1*2)+1", "=A1*2+1"},
+		{"=1-(2-3)", "=1-(2-3)"},
+		{"=(1-2)-3", "=1-2-3"},
+		{"=2^(3^2)", "=2^(3^2)"},
+		{"=(2^3)^2", "=2^3^2"},
+		{"=-(A1+1)", "=-(A1+1)"},
+		{"=(-A1)^2", "=-A1^2"},
+		{"=-(A1^2)", "=-(A1^2)"},
+		{"=2^-2", "=2^-2"},
+		{"=(-5)%", "=-5%"},
+		{"=(A1=B1)&\"x\"", "=(A1=B1)&\"x\""},
+		{"=A1&(B1=C1)", "=A1&(B1=C1)"},
+		{"=(A1)", "=A1"},
+		{"=SUM((A1:A3,C1:C3))", "=SUM((A1:A3,C1:C3))"},
+		{"=SUM(((A1,B1)),(C1))", "=SUM((A1,B1),C1)"},
+		{"=LARGE((A1,B5,C9),2)", "=LARGE((A1,B5,C9),2)"},
+		{"=(A1,B1)", "=(A1,B1)"},
+		{"=@(A1:B2 C1:D2)", "=@A1:B2 C1:D2"},
+		{"=A1:C5 B2:D8", "=A1:C5 B2:D8"},
+		{"=SUM(A2#)", "=SUM(A2#)"},
+		{"=Sheet1!A1:B2", "=Sheet1!A1:B2"},
+		{"='My Sheet'!B2:C9", "='My Sheet'!B2:C9"},
+		{"=SUM(Jan:Dec!B2)", "=SUM(Jan:Dec!B2)"},
+		{"=[Book.xlsx]Sheet1!A1+[1]Sheet1!A1", "=[Book.xlsx]Sheet1!A1+[1]Sheet1!A1"},
+		{"=Table1[[#Headers],[Col A]:[Col C]]", "=Table1[[#Headers],[Col A]:[Col C]]"},
+		{"=LAMBDA(x,x+1)(2)", "=LAMBDA(x,x+1)(2)"},
+		{"=(LAMBDA(x,x+1))(2)", "=LAMBDA(x,x+1)(2)"},
+		{"=LET(f,LAMBDA(x,x*2),f(3))", "=LET(f,LAMBDA(x,x*2),f(3))"},
+		{"=IF(A1,,0)", "=IF(A1,,0)"},
+		{"={1,2;3,4}", "={1,2;3,4}"},
+		{"=1.5E+10*.5", "=1.5E+10*.5"},
+		{"=\"a\"\"b\"&#N/A", "=\"a\"\"b\"&#N/A"},
+		{"=TRUE<>FALSE", "=TRUE<>FALSE"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		var got = Format(node)
+		if got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+			continue
+		}
+		// the output is valid and formats to itself
+		reparsed, err := NewParser(got).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", got, err)
+			continue
+		}
+		if again := Format(reparsed); again != got {
+			t.Errorf("For input '%s', expected '%s', got '%s'", got, got, again)
+		}
+	}
+}
+
+func TestFormatPreserveParens(t *testing.T) {
+	tests := []string{
+		"=((A1+B1))*2",
+		"=(A1*2)+1",
+		"=(-5)%",
+		"=SUM(((A1,B1)),(C1))",
+		"=(LAMBDA(x,x+1))(2)",
+		"=IF((A1>0),(1),-(2))",
+	}
+	var printer = &Printer{PreserveParens: true}
+	for _, src := range tests {
+		node, err := NewParser(src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", src, err)
+			continue
+		}
+		if got := printer.Format(node); got != src {
+			t.Errorf("For input '%s', expected '%s', got '%s'", src, src, got)
+		}
+	}
+}
+
+func TestFormatConstructed(t *testing.T) {
+	var number = func(raw string) Node {
+		return LiteralExpr{Value: &Token{Type: Number, Raw: raw}}
+	}
+	var binary = func(left Node, op TokenType, raw string, right Node) Node {
+		return BinaryExpr{Left: left, Operator: &Token{Type: op, Raw: raw}, Right: right}
+	}
+	tests := []struct {
+		node     Node
+		expected string
+	}{
+		{binary(binary(number("1"), Plus, "+", number("2")), Multiply, "*", number("3")), "=(1+2)*3"},
+		{binary(number("1"), Minus, "-", binary(number("2"), Minus, "-", number("3"))), "=1-(2-3)"},
+		{binary(number("1"), Comma, ",", number("2")), "=(1,2)"},
+		{UnaryExpr{Operator: &Token{Type: Percent, Raw: "%"}, Operand: binary(number("1"), Plus, "+", number("2"))}, "=(1+2)%"},
+		{UnaryExpr{Operator: &Token{Type: ImplicitIntersection, Raw: "@"}, Operand: number("-1")}, "=@(-1)"},
+		{CallExpr{Callee: IdentExpr{Name: &Token{Type: Ident, Raw: "f"}}, Arguments: []Node{number("1")}}, "=(f)(1)"},
+	}
+	for _, test := range tests {
+		if got := Format(test.node); got != test.expected {
+			t.Errorf("For node '%s', expected '%s', got '%s'", test.node, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,671 @@
+package excelformulaparser
+
+import (
+	"fmt"
+	"slices"
+)
+
+// A Cursor describes a node encountered during Rewrite.
+// Information about the node and its parent is available
+// from the Node, Parent, Name, and Index methods.
+//
+// The methods Replace, Delete, InsertBefore, and InsertAfter
+// can be used to change the AST without disturbing Rewrite.
+type Cursor struct {
+	parent  Node
+	name    string
+	index   int  // the index in the parent list, or -1
+	list    bool // whether the node is an element of a list that supports Delete and Insert
+	node    Node
+	deleted bool
+	before  []Node // nodes inserted before the node
+	after   []Node // nodes inserted after the node
+	changed *bool  // set when the AST is changed
+}
+
+// Node returns the current Node.
+func (c *Cursor) Node() Node { return c.node }
+
+// Parent returns the parent of the current Node, nil for the root.
+func (c *Cursor) Parent() Node { return c.parent }
+
+// Name returns the name of the parent Node field that contains the current Node
+// (e.g., "Left", "Arguments" or "Body"), or "" for the root.
+func (c *Cursor) Name() string { return c.name }
+
+// Index reports the index of the current Node in the slice of Nodes that
+// contains it, or a value < 0 if the current Node is not part of a slice.
+// The index of the current node changes if InsertBefore is called while
+// processing the current node. For ArrayExpr elements it is the column
+// within the row, and for LetExpr values the index of the binding.
+func (c *Cursor) Index() int { return c.index }
+
+// Replace replaces the current Node with n.
+// The replacement node is not walked by Rewrite.
+func (c *Cursor) Replace(n Node) {
+	c.node = n
+	*c.changed = true
+}
+
+// Delete deletes the current Node from its containing slice.
+// If the current Node is not part of a slice that supports deletion, namely
+// the Arguments of FunCallExpr and CallExpr or the Elements of ArrayExpr,
+// Delete panics.
+func (c *Cursor) Delete() {
+	if !c.list {
+		panic(fmt.Sprintf("excelformulaparser.Cursor.Delete: %s is not a deletable list element", c.name))
+	}
+	c.deleted = true
+	*c.changed = true
+}
+
+// InsertAfter inserts n after the current Node in its containing slice.
+// If the current Node is not part of a list, InsertAfter panics.
+// Rewrite does not walk n.
+func (c *Cursor) InsertAfter(n Node) {
+	if !c.list {
+		panic(fmt.Sprintf("excelformulaparser.Cursor.InsertAfter: %s is not a list element", c.name))
+	}
+	c.after = append([]Node{n}, c.after...)
+	*c.changed = true
+}
+
+// InsertBefore inserts n before the current Node in its containing slice.
+// If the current Node is not part of a list, InsertBefore panics.
+// Rewrite does not walk n.
+func (c *Cursor) InsertBefore(n Node) {
+	if !c.list {
+		panic(fmt.Sprintf("excelformulaparser.Cursor.InsertBefore: %s is not a list element", c.name))
+	}
+	c.before = append(c.before, n)
+	c.index++
+	*c.changed = true
+}
+
+// Rewrite traverses an AST recursively, starting with root, and calling pre
+// and post for each node. Either function may be nil.
+//
+// If pre is not nil, it is called for each node before the node's children
+// are traversed (pre-order). If pre returns false, no children are traversed,
+// and post is not called for that node.
+//
+// If post is not nil, and a prior call of pre didn't return false, post is
+// called for each node after its children are traversed (post-order). If post
+// returns false, traversal is terminated and Rewrite returns immediately.
+//
+// Nodes are values, so the tree is never modified in place: Rewrite returns
+// a new tree and leaves root unchanged. If the tree was changed through a
+// Cursor, the positions of all nodes and tokens of the result are recomputed
+// from its Source text, and missing tokens (e.g., the parentheses of a
+// constructed FunCallExpr) are created. Otherwise root is returned as is.
+func Rewrite(root Node, pre, post func(*Cursor) bool) Node {
+	var a = &application{pre: pre, post: post}
+	var c = a.cursor(nil, "", -1, false, root)
+	a.visit(c)
+	if !a.changed {
+		return root
+	}
+	return relayout(c.node, root)
+}
+
+type application struct {
+	pre, post func(*Cursor) bool
+	changed   bool
+	stopped   bool
+}
+
+func (a *application) cursor(parent Node, name string, index int, list bool, node Node) *Cursor {
+	return &Cursor{parent: parent, name: name, index: index, list: list, node: node, changed: &a.changed}
+}
+
+func (a *application) visit(c *Cursor) {
+	if a.stopped || c.node == nil {
+		return
+	}
+	if a.pre != nil && !a.pre(c) {
+		return
+	}
+	if c.deleted || c.node == nil {
+		return
+	}
+	c.node = a.children(c.node)
+	if a.stopped {
+		return
+	}
+	if a.post != nil && !a.post(c) {
+		a.stopped = true
+	}
+}
+
+// apply visits a node that is not a list element and returns its replacement.
+func (a *application) apply(parent Node, name string, index int, node Node) Node {
+	var c = a.cursor(parent, name, index, false, node)
+	a.visit(c)
+	return c.node
+}
+
+// applyList visits the elements of a list and returns the resulting list.
+// separators[i] is the token between list[i] and list[i+1]; the separators of
+// deleted elements are dropped and inserted elements get none.
+func (a *application) applyList(parent Node, name string, list []Node, separators []*Token) ([]Node, []*Token) {
+	var nodes []Node
+	var seps []*Token
+	var add = func(node Node, separator *Token) {
+		if len(nodes) > 0 {
+			seps = append(seps, separator)
+		}
+		nodes = append(nodes, node)
+	}
+	for i, node := range list {
+		var c = a.cursor(parent, name, len(nodes), true, node)
+		a.visit(c)
+		var separator *Token // the separator before the element
+		if i > 0 && i-1 < len(separators) {
+			separator = separators[i-1]
+		}
+		for _, n := range c.before {
+			add(n, separator)
+			separator = nil
+		}
+		if !c.deleted {
+			add(c.node, separator)
+		}
+		for _, n := range c.after {
+			add(n, nil)
+		}
+	}
+	if len(seps) == 0 {
+		seps = nil
+	}
+	return nodes, seps
+}
+
+// children rebuilds node with its children visited.
+func (a *application) children(node Node) Node {
+	switch n := node.(type) {
+	case LiteralExpr, CellExpr, R1C1Expr, IdentExpr, StructuredRefExpr, EmptyArgExpr:
+		return n
+	case RangeExpr:
+		n.Begin = a.apply(n, "Begin", -1, n.Begin)
+		var ends = make([]Node, len(n.Ends))
+		for i, end := range n.Ends {
+			ends[i] = a.apply(n, "Ends", i, end)
+		}
+		n.Ends = ends
+		return n
+	case SheetRefExpr:
+		n.Ref = a.apply(n, "Ref", -1, n.Ref)
+		return n
+	case Sheet3DRefExpr:
+		n.Ref = a.apply(n, "Ref", -1, n.Ref)
+		return n
+	case ExternalRefExpr:
+		n.Ref = a.apply(n, "Ref", -1, n.Ref)
+		return n
+	case BinaryExpr:
+		n.Left = a.apply(n, "Left", -1, n.Left)
+		n.Right = a.apply(n, "Right", -1, n.Right)
+		return n
+	case UnaryExpr:
+		n.Operand = a.apply(n, "Operand", -1, n.Operand)
+		return n
+	case ParenthesizedExpr:
+		n.Inner = a.apply(n, "Inner", -1, n.Inner)
+		return n
+	case FunCallExpr:
+		n.Arguments, n.Separators = a.applyList(n, "Arguments", n.Arguments, n.Separators)
+		return n
+	case CallExpr:
+		n.Callee = a.apply(n, "Callee", -1, n.Callee)
+		n.Arguments, n.Separators = a.applyList(n, "Arguments", n.Arguments, n.Separators)
+		return n
+	case LetExpr:
+		var bindings = make([]LetBinding, len(n.Bindings))
+		for i, binding := range n.Bindings {
+			bindings[i] = LetBinding{Name: binding.Name, Value: a.apply(n, "Bindings", i, binding.Value)}
+		}
+		n.Bindings = bindings
+		n.Body = a.apply(n, "Body", -1, n.Body)
+		return n
+	case LambdaExpr:
+		n.Body = a.apply(n, "Body", -1, n.Body)
+		return n
+	case ArrayExpr:
+		var rows, columns = splitArraySeparators(n)
+		var elements = make([][]Node, len(n.Elements))
+		var separators []*Token
+		for i, row := range n.Elements {
+			if i > 0 {
+				separators = append(separators, rows[i-1])
+			}
+			var seps []*Token
+			elements[i], seps = a.applyList(n, "Elements", row, columns[i])
+			separators = append(separators, seps...)
+		}
+		n.Elements = elements
+		n.Separators = separators
+		return n
+	default:
+		panic(fmt.Sprintf("excelformulaparser.Rewrite: unexpected node type %T", n))
+	}
+}
+
+// splitArraySeparators returns the row separators of an array, and the column
+// separators of each row.
+func splitArraySeparators(n ArrayExpr) (rows []*Token, columns [][]*Token) {
+	var i = 0 // the next separator
+	var next = func() *Token {
+		if i >= len(n.Separators) {
+			return nil
+		}
+		i++
+		return n.Separators[i-1]
+	}
+	columns = make([][]*Token, len(n.Elements))
+	for j, row := range n.Elements {
+		if j > 0 {
+			rows = append(rows, next())
+		}
+		for k := 1; k < len(row); k++ {
+			columns[j] = append(columns[j], next())
+		}
+	}
+	return rows, columns
+}
+
+// A nodeMapper rebuilds the tokens and children of a node, see mapNode.
+type nodeMapper interface {
+	// token maps a token of the source text. If t is nil, text is the
+	// text of the missing token, or "" if it is optional.
+	token(t *Token, typ TokenType, text string) *Token
+	// alias maps a token that is not part of the source text, which is
+	// written as the already mapped token of.
+	alias(t, of *Token) *Token
+	// ref returns the mapped token of a token referenced by a node
+	// (e.g., IdentExpr.Binding), or t if it was not mapped.
+	ref(t *Token) *Token
+	// node maps a child node.
+	node(n Node) Node
+	// span returns the position of a node whose first and last tokens
+	// start and end at start and end.
+	span(b baseNode, start, end Pos) baseNode
+}
+
+// mapNode returns a copy of node with its tokens and children mapped by m, in source order.
+func mapNode(node Node, m nodeMapper) Node {
+	var started bool
+	var start, end Pos
+	var token = func(t *Token, typ TokenType, text string) *Token {
+		if t = m.token(t, typ, text); t != nil {
+			if !started {
+				start, started = t.Start, true
+			}
+			end = t.End
+		}
+		return t
+	}
+	var child = func(n Node) Node {
+		if n == nil {
+			return nil
+		}
+		if n = m.node(n); n != nil {
+			if _, ok := n.(EmptyArgExpr); !ok {
+				if !started {
+					start, started = n.Start(), true
+				}
+				end = n.End()
+			}
+		}
+		return n
+	}
+	// separator maps old[i], and appends it to seps unless it is missing in both.
+	var separator = func(old []*Token, i int, typ TokenType, text string, seps *[]*Token) *Token {
+		var t *Token
+		if i < len(old) {
+			t = old[i]
+		}
+		if t = token(t, typ, text); t != nil || i < len(old) {
+			*seps = append(*seps, t)
+		}
+		return t
+	}
+	// arguments maps an argument list, where an EmptyArgExpr is positioned at
+	// the start of the following separator or closing parenthesis.
+	var arguments = func(args []Node, old []*Token, close func() *Token) ([]Node, []*Token) {
+		var list = make([]Node, len(args))
+		var seps []*Token
+		var empty = -1
+		var next = func(t *Token) {
+			if empty >= 0 && t != nil {
+				list[empty] = EmptyArgExpr{baseNode: m.span(list[empty].(EmptyArgExpr).baseNode, t.Start, t.Start)}
+			}
+			empty = -1
+		}
+		for i, arg := range args {
+			if i > 0 {
+				next(separator(old, i-1, Comma, ",", &seps))
+			}
+			list[i] = child(arg)
+			if _, ok := list[i].(EmptyArgExpr); ok {
+				empty = i
+			}
+		}
+		next(close())
+		if args == nil {
+			list = nil
+		}
+		return list, seps
+	}
+
+	switch n := node.(type) {
+	case LiteralExpr:
+		if n.LocalValue != nil {
+			n.LocalValue = token(n.LocalValue, BoolLiteral, "")
+			n.Value = m.alias(n.Value, n.LocalValue)
+		} else {
+			n.Value = token(n.Value, Number, "")
+		}
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case CellExpr:
+		n.Ident = token(n.Ident, Cell, "")
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case R1C1Expr:
+		n.Ident = token(n.Ident, R1C1Reference, "")
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case IdentExpr:
+		n.Name = token(n.Name, Ident, "")
+		n.Binding = m.ref(n.Binding)
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case RangeExpr:
+		n.Begin = child(n.Begin)
+		var colons []*Token
+		var ends = make([]Node, len(n.Ends))
+		for i, e := range n.Ends {
+			separator(n.Colons, i, Colon, ":", &colons)
+			ends[i] = child(e)
+		}
+		n.Colons, n.Ends = colons, ends
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case SheetRefExpr:
+		n.SheetToken = token(n.SheetToken, Ident, n.Sheet)
+		n.Exclamation = token(n.Exclamation, Exclamation, "!")
+		n.Ref = child(n.Ref)
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case Sheet3DRefExpr:
+		var quoted = n.FirstSheetToken != nil && n.LastSheetToken == nil // e.g., 'Jan 2024:Dec 2024'!C4
+		n.FirstSheetToken = token(n.FirstSheetToken, Ident, n.FirstSheet)
+		if !quoted {
+			n.Colon = token(n.Colon, Colon, ":")
+			n.LastSheetToken = token(n.LastSheetToken, Ident, n.LastSheet)
+		}
+		n.Exclamation = token(n.Exclamation, Exclamation, "!")
+		n.Ref = child(n.Ref)
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case ExternalRefExpr:
+		var prefix = make([]*Token, len(n.Prefix))
+		for i, t := range n.Prefix {
+			prefix[i] = token(t, Ident, "")
+		}
+		if n.Prefix == nil {
+			prefix = nil
+		}
+		n.Prefix = prefix
+		n.Exclamation = token(n.Exclamation, Exclamation, "!")
+		n.Ref = child(n.Ref)
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case StructuredRefExpr:
+		n.Table = token(n.Table, Ident, "")
+		n.Specifier = token(n.Specifier, Bracketed, "")
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case BinaryExpr:
+		n.Left = child(n.Left)
+		n.Operator = token(n.Operator, Ident, "")
+		n.Right = child(n.Right)
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case UnaryExpr:
+		if isPostfixOperator(n.Operator) {
+			n.Operand = child(n.Operand)
+			n.Operator = token(n.Operator, Ident, "")
+		} else {
+			n.Operator = token(n.Operator, Ident, "")
+			n.Operand = child(n.Operand)
+		}
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case ParenthesizedExpr:
+		n.ParenOpen = token(n.ParenOpen, ParenOpen, "(")
+		n.Inner = child(n.Inner)
+		n.ParenClose = token(n.ParenClose, ParenClose, ")")
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case FunCallExpr:
+		if n.LocalName != nil {
+			n.LocalName = token(n.LocalName, Ident, "")
+			n.Name = m.alias(n.Name, n.LocalName)
+		} else {
+			n.Name = token(n.Name, Ident, "")
+		}
+		n.ParanOpen = token(n.ParanOpen, ParenOpen, "(")
+		n.Arguments, n.Separators = arguments(n.Arguments, n.Separators, func() *Token {
+			n.ParanClose = token(n.ParanClose, ParenClose, ")")
+			return n.ParanClose
+		})
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case CallExpr:
+		n.Callee = child(n.Callee)
+		n.ParenOpen = token(n.ParenOpen, ParenOpen, "(")
+		n.Arguments, n.Separators = arguments(n.Arguments, n.Separators, func() *Token {
+			n.ParenClose = token(n.ParenClose, ParenClose, ")")
+			return n.ParenClose
+		})
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case LetExpr:
+		n.Name = token(n.Name, Ident, "LET")
+		n.ParenOpen = token(n.ParenOpen, ParenOpen, "(")
+		var bindings = make([]LetBinding, len(n.Bindings))
+		var seps []*Token
+		for i, binding := range n.Bindings {
+			bindings[i].Name = token(binding.Name, Ident, "")
+			separator(n.Separators, 2*i, Comma, ",", &seps)
+			bindings[i].Value = child(binding.Value)
+			separator(n.Separators, 2*i+1, Comma, ",", &seps)
+		}
+		n.Bindings, n.Separators = bindings, seps
+		n.Body = child(n.Body)
+		n.ParenClose = token(n.ParenClose, ParenClose, ")")
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case LambdaExpr:
+		n.Name = token(n.Name, Ident, "LAMBDA")
+		n.ParenOpen = token(n.ParenOpen, ParenOpen, "(")
+		var params = make([]*Token, len(n.Params))
+		var seps []*Token
+		for i, param := range n.Params {
+			params[i] = token(param, Ident, "")
+			separator(n.Separators, i, Comma, ",", &seps)
+		}
+		if n.Params == nil {
+			params = nil
+		}
+		n.Params, n.Separators = params, seps
+		n.Body = child(n.Body)
+		n.ParenClose = token(n.ParenClose, ParenClose, ")")
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	case EmptyArgExpr:
+		return n // positioned by the argument list
+	case ArrayExpr:
+		n.BraceOpen = token(n.BraceOpen, BraceOpen, "{")
+		var elements = make([][]Node, len(n.Elements))
+		var seps []*Token
+		for i, row := range n.Elements {
+			if i > 0 {
+				separator(n.Separators, len(seps), Semicolon, ";", &seps)
+			}
+			elements[i] = make([]Node, len(row))
+			for j, element := range row {
+				if j > 0 {
+					separator(n.Separators, len(seps), Comma, ",", &seps)
+				}
+				elements[i][j] = child(element)
+			}
+		}
+		n.Elements, n.Separators = elements, seps
+		n.BraceClose = token(n.BraceClose, BraceClose, "}")
+		n.baseNode = m.span(n.baseNode, start, end)
+		return n
+	default:
+		panic(fmt.Sprintf("excelformulaparser: unexpected node type %T", n))
+	}
+}
+
+// relayout returns a copy of node with all tokens copied and positioned as if
+// its Source text were parsed. The leading trivia (e.g., the '=' prefix) and
+// the trailing trivia of the formula stay at its ends, even if the first or
+// last token of orig moved, e.g. when orig is wrapped in another node.
+func relayout(node, orig Node) Node {
+	var l = &layout{tokens: map[*Token]*Token{}}
+	l.first, l.lastOrig = boundaryTokens(orig)
+	var result = l.node(node)
+	if l.last != nil && l.lastOrig != nil {
+		l.last.Trailing = l.trivia(l.lastOrig.Trailing)
+	}
+	return result
+}
+
+type layout struct {
+	pos      Pos  // the position of the last character
+	started  bool // whether a character was positioned
+	tokens   map[*Token]*Token
+	first    *Token // The first token of the original formula, whose leading trivia (e.g., the '=' prefix) moves to the first token
+	lastOrig *Token // The last token of the original formula, whose trailing trivia moves to the last token
+	last     *Token // The last positioned token
+}
+
+// advance moves to the next character, like lexer.nextch.
+func (l *layout) advance(ch rune) {
+	switch {
+	case !l.started:
+		l.pos, l.started = Pos{Line: 1, Column: 1}, true
+	case ch == '\n':
+		l.pos.nextLine()
+	default:
+		l.pos.nextColumn()
+	}
+}
+
+// place copies t and positions it at the next characters.
+func (l *layout) place(t *Token) *Token {
+	var c = *t
+	var first = true
+	for _, ch := range t.Raw {
+		l.advance(ch)
+		if first {
+			c.Start, first = l.pos, false
+		}
+	}
+	if first {
+		c.Start = l.pos // an empty token
+	}
+	c.End = l.pos
+	l.tokens[t] = &c
+	return &c
+}
+
+func (l *layout) trivia(list []*Token) []*Token {
+	if list == nil {
+		return nil
+	}
+	var trivia = make([]*Token, len(list))
+	for i, t := range list {
+		trivia[i] = l.place(t)
+	}
+	return trivia
+}
+
+func (l *layout) token(t *Token, typ TokenType, text string) *Token {
+	if t == nil {
+		if text == "" {
+			return nil
+		}
+		t = newToken(Pos{}, Pos{}, typ, text)
+	}
+	var leading []*Token
+	if !l.started && l.first != nil {
+		leading = append(leading, l.first.Leading...)
+	}
+	for _, trivia := range t.Leading {
+		if l.first == nil || !slices.Contains(l.first.Leading, trivia) {
+			leading = append(leading, trivia)
+		}
+	}
+	leading = l.trivia(leading)
+	var c = l.place(t)
+	c.Leading = leading
+	c.Trailing = nil // set on the last token by relayout
+	l.last = c
+	return c
+}
+
+func (l *layout) alias(t, of *Token) *Token {
+	if t == nil {
+		return nil
+	}
+	var c = *t
+	c.Start, c.End = of.Start, of.End
+	l.tokens[t] = &c
+	return &c
+}
+
+func (l *layout) ref(t *Token) *Token {
+	if c, ok := l.tokens[t]; ok {
+		return c
+	}
+	return t
+}
+
+func (l *layout) node(n Node) Node {
+	return mapNode(n, l)
+}
+
+func (l *layout) span(_ baseNode, start, end Pos) baseNode {
+	return newBaseNode(start, end)
+}
+
+// boundaryTokens returns the first and the last token of node in source order.
+func boundaryTokens(node Node) (first, last *Token) {
+	var b = &boundaries{}
+	b.node(node)
+	return b.first, b.last
+}
+
+type boundaries struct {
+	first, last *Token
+}
+
+func (b *boundaries) token(t *Token, _ TokenType, _ string) *Token {
+	if t != nil {
+		if b.first == nil {
+			b.first = t
+		}
+		b.last = t
+	}
+	return t
+}
+
+func (b *boundaries) alias(t, _ *Token) *Token           { return t }
+func (b *boundaries) ref(t *Token) *Token                { return t }
+func (b *boundaries) node(n Node) Node                   { return mapNode(n, b) }
+func (b *boundaries) span(n baseNode, _, _ Pos) baseNode { return n }
@@ -0,0 +1,211 @@
+package excelformulaparser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// positions lists the positions of all nodes of node in Inspect order.
+func positions(node Node) string {
+	var list []string
+	Inspect(node, func(n Node) bool {
+		if n != nil {
+			list = append(list, fmt.Sprintf("%v-%v", n.Start(), n.End()))
+		}
+		return true
+	})
+	return strings.Join(list, " ")
+}
+
+func number(raw string) LiteralExpr {
+	return LiteralExpr{Value: &Token{Type: Number, Raw: raw}}
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		src      string
+		pre      func(*Cursor) bool
+		post     func(*Cursor) bool
+		expected string
+	}{
+		{
+			src: "=SUM(A1, A1*2)",
+			pre: func(c *Cursor) bool {
+				if cell, ok := c.Node().(CellExpr); ok && cell.Ident.Raw == "A1" {
+					c.Replace(CellExpr{Ident: &Token{Type: Cell, Raw: "$C$10", Leading: cell.Ident.Leading}})
+				}
+				return true
+			},
+			expected: "=SUM($C$10, $C$10*2)",
+		},
+		{
+			src: "=SUM(A1:A3) + sum(B1)",
+			post: func(c *Cursor) bool {
+				if call, ok := c.Node().(FunCallExpr); ok && strings.EqualFold(call.Name.Raw, "SUM") {
+					call.Name = &Token{Type: Ident, Raw: "SUMPRODUCT", Leading: call.Name.Leading}
+					c.Replace(call)
+				}
+				return true
+			},
+			expected: "=SUMPRODUCT(A1:A3) + SUMPRODUCT(B1)",
+		},
+		{
+			src: "= VLOOKUP(A1, B:C, 2, FALSE)\n",
+			post: func(c *Cursor) bool {
+				if call, ok := c.Node().(FunCallExpr); ok && call.Name.Raw == "VLOOKUP" {
+					c.Replace(FunCallExpr{
+						Name:      &Token{Type: Ident, Raw: "IFERROR"},
+						Arguments: []Node{call, number("0")},
+					})
+				}
+				return true
+			},
+			expected: "= IFERROR(VLOOKUP(A1, B:C, 2, FALSE),0)\n",
+		},
+		{
+			src: "=SUM(A1, B1, C1)",
+			pre: func(c *Cursor) bool {
+				if cell, ok := c.Node().(CellExpr); ok && cell.Ident.Raw == "B1" {
+					c.Delete()
+				}
+				return true
+			},
+			expected: "=SUM(A1, C1)",
+		},
+		{
+			src: "=SUM(A1, B1, C1)",
+			pre: func(c *Cursor) bool {
+				if cell, ok := c.Node().(CellExpr); ok && cell.Ident.Raw == "A1" {
+					c.Delete()
+				}
+				return true
+			},
+			expected: "=SUM( B1, C1)",
+		},
+		{
+			src: "={1,2;3,4}",
+			pre: func(c *Cursor) bool {
+				if lit, ok := c.Node().(LiteralExpr); ok && lit.Value.Raw == "1" {
+					c.InsertAfter(number("9"))
+				}
+				if lit, ok := c.Node().(LiteralExpr); ok && lit.Value.Raw == "4" {
+					c.InsertBefore(number("8"))
+				}
+				return true
+			},
+			expected: "={1,9,2;3,8,4}",
+		},
+		{
+			src: "=IF(A1,,0)",
+			pre: func(c *Cursor) bool {
+				if _, ok := c.Node().(EmptyArgExpr); ok {
+					c.Replace(number("1"))
+				}
+				return true
+			},
+			expected: "=IF(A1,1,0)",
+		},
+		{
+			src: "=A1+B1",
+			post: func(c *Cursor) bool {
+				if c.Parent() == nil {
+					c.Replace(ParenthesizedExpr{Inner: c.Node()})
+				}
+				return true
+			},
+			expected: "=(A1+B1)",
+		},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		var result = Rewrite(node, test.pre, test.post)
+		if got := Source(result); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+		if got := Source(node); got != test.src {
+			t.Errorf("For input '%s', expected the original tree to be unchanged, got '%s'", test.src, got)
+		}
+		// the positions are the ones of the rewritten formula
+		reparsed, err := NewParser(test.expected).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.expected, err)
+			continue
+		}
+		if got, expected := positions(result), positions(reparsed); got != expected {
+			t.Errorf("For input '%s', expected positions '%s', got '%s'", test.src, expected, got)
+		}
+	}
+}
+
+func TestRewriteCursor(t *testing.T) {
+	node, err := NewParser("=IF(A1, -B1, {1,2})").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var visits []string
+	var result = Rewrite(node, func(c *Cursor) bool {
+		var parent = "nil"
+		if c.Parent() != nil {
+			parent = strings.TrimPrefix(fmt.Sprintf("%T", c.Parent()), "excelformulaparser.")
+		}
+		visits = append(visits, fmt.Sprintf("%s.%s[%d]", parent, c.Name(), c.Index()))
+		return true
+	}, nil)
+	var expected = "nil.[-1] FunCallExpr.Arguments[0] FunCallExpr.Arguments[1] UnaryExpr.Operand[-1] FunCallExpr.Arguments[2] ArrayExpr.Elements[0] ArrayExpr.Elements[1]"
+	if got := strings.Join(visits, " "); got != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, got)
+	}
+	if result.(FunCallExpr).Name != node.(FunCallExpr).Name {
+		t.Errorf("Expected the unchanged tree to be returned")
+	}
+
+	// post returning false stops the traversal
+	var count = 0
+	Rewrite(node, nil, func(c *Cursor) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Errorf("Expected 1 post call, got %d", count)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected Delete of a non-list node to panic")
+		}
+	}()
+	Rewrite(node, func(c *Cursor) bool {
+		if cell, ok := c.Node().(CellExpr); ok && cell.Ident.Raw == "B1" {
+			c.Delete() // the operand of -B1
+		}
+		return true
+	}, nil)
+}
+
+func TestRewriteBinding(t *testing.T) {
+	node, err := NewParser("=LET(x, 1, x + 1)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var result = Rewrite(node, func(c *Cursor) bool {
+		if c.Name() == "Bindings" {
+			c.Replace(number("10"))
+		}
+		return true
+	}, nil).(LetExpr)
+	if got := Source(result); got != "=LET(x,10, x + 1)" {
+		t.Errorf("Expected '=LET(x,10, x + 1)', got '%s'", got)
+	}
+	var x = result.Body.(BinaryExpr).Left.(IdentExpr)
+	if x.Binding != result.Bindings[0].Name {
+		t.Errorf("Expected the binding to refer to the rewritten declaration")
+	}
+	if x.Binding == node.(LetExpr).Bindings[0].Name || x.Binding.End != (Pos{Line: 1, Column: 6}) {
+		t.Errorf("Expected a copied declaration token, got %v", x.Binding)
+	}
+}
@@ -0,0 +1,166 @@
+package excelformulaparser
+
+import "strings"
+
+// Source returns the text of node from its tokens, including their trivia.
+// For an unchanged tree returned by Parse, this is the formula byte for byte,
+// e.g. "= SUM( A1 ,\n B1 )". Edited parts of a tree keep the whitespace of the
+// tokens around them, and missing tokens (e.g., the parentheses of a
+// constructed FunCallExpr) are printed in the DefaultLocale syntax.
+func Source(node Node) string {
+	var w source
+	w.node(node)
+	return w.sb.String()
+}
+
+type source struct {
+	sb strings.Builder
+}
+
+// token prints t with its trivia, or fallback if t is nil.
+func (w *source) token(t *Token, fallback string) {
+	if t == nil {
+		w.sb.WriteString(fallback)
+		return
+	}
+	for _, trivia := range t.Leading {
+		w.sb.WriteString(trivia.Raw)
+	}
+	w.sb.WriteString(t.Raw)
+	for _, trivia := range t.Trailing {
+		w.sb.WriteString(trivia.Raw)
+	}
+}
+
+// separator prints the i-th of separators, or fallback if there are not enough.
+func (w *source) separator(separators []*Token, i int, fallback string) {
+	if i < len(separators) {
+		w.token(separators[i], fallback)
+		return
+	}
+	w.sb.WriteString(fallback)
+}
+
+func (w *source) node(node Node) {
+	switch n := node.(type) {
+	case LiteralExpr:
+		if n.LocalValue != nil {
+			w.token(n.LocalValue, "") // the boolean as written in a localized formula
+		} else {
+			w.token(n.Value, "")
+		}
+	case CellExpr:
+		w.token(n.Ident, "")
+	case R1C1Expr:
+		w.token(n.Ident, "")
+	case IdentExpr:
+		w.token(n.Name, "")
+	case RangeExpr:
+		w.node(n.Begin)
+		for i, end := range n.Ends {
+			w.separator(n.Colons, i, ":")
+			w.node(end)
+		}
+	case SheetRefExpr:
+		w.token(n.SheetToken, n.Sheet)
+		w.token(n.Exclamation, "!")
+		w.node(n.Ref)
+	case Sheet3DRefExpr:
+		w.token(n.FirstSheetToken, n.FirstSheet)
+		if n.LastSheetToken != nil || n.FirstSheetToken == nil {
+			w.token(n.Colon, ":")
+			w.token(n.LastSheetToken, n.LastSheet)
+		}
+		w.token(n.Exclamation, "!")
+		w.node(n.Ref)
+	case ExternalRefExpr:
+		for _, token := range n.Prefix {
+			w.token(token, "")
+		}
+		w.token(n.Exclamation, "!")
+		w.node(n.Ref)
+	case StructuredRefExpr:
+		if n.Table != nil {
+			w.token(n.Table, "")
+		}
+		w.token(n.Specifier, "")
+	case BinaryExpr:
+		w.node(n.Left)
+		w.token(n.Operator, "")
+		w.node(n.Right)
+	case UnaryExpr:
+		if isPostfixOperator(n.Operator) {
+			w.node(n.Operand)
+			w.token(n.Operator, "")
+		} else {
+			w.token(n.Operator, "")
+			w.node(n.Operand)
+		}
+	case ParenthesizedExpr:
+		w.token(n.ParenOpen, "(")
+		w.node(n.Inner)
+		w.token(n.ParenClose, ")")
+	case FunCallExpr:
+		if n.LocalName != nil {
+			w.token(n.LocalName, "") // the name as written in a localized formula
+		} else {
+			w.token(n.Name, "")
+		}
+		w.token(n.ParanOpen, "(")
+		w.arguments(n.Arguments, n.Separators)
+		w.token(n.ParanClose, ")")
+	case CallExpr:
+		w.node(n.Callee)
+		w.token(n.ParenOpen, "(")
+		w.arguments(n.Arguments, n.Separators)
+		w.token(n.ParenClose, ")")
+	case LetExpr:
+		w.token(n.Name, "LET")
+		w.token(n.ParenOpen, "(")
+		for i, binding := range n.Bindings {
+			w.token(binding.Name, "")
+			w.separator(n.Separators, 2*i, ",")
+			w.node(binding.Value)
+			w.separator(n.Separators, 2*i+1, ",")
+		}
+		w.node(n.Body)
+		w.token(n.ParenClose, ")")
+	case LambdaExpr:
+		w.token(n.Name, "LAMBDA")
+		w.token(n.ParenOpen, "(")
+		for i, param := range n.Params {
+			w.token(param, "")
+			w.separator(n.Separators, i, ",")
+		}
+		w.node(n.Body)
+		w.token(n.ParenClose, ")")
+	case EmptyArgExpr:
+		// an omitted argument has no tokens
+	case ArrayExpr:
+		w.token(n.BraceOpen, "{")
+		var i = 0 // the next separator
+		for j, row := range n.Elements {
+			if j > 0 {
+				w.separator(n.Separators, i, ";")
+				i++
+			}
+			for k, element := range row {
+				if k > 0 {
+					w.separator(n.Separators, i, ",")
+					i++
+				}
+				w.node(element)
+			}
+		}
+		w.token(n.BraceClose, "}")
+	}
+}
+
+func (w *source) arguments(arguments []Node, separators []*Token) {
+	for i, arg := range arguments {
+		if i > 0 {
+			w.separator(separators, i-1, ",")
+		}
+		w.node(arg)
+	}
+}
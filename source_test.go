@@ -0,0 +1,72 @@
+package excelformulaparser
+
+import "testing"
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		options ParseOptions
+		src     string
+	}{
+		{ParseOptions{}, "=A1+B1"},
+		{ParseOptions{}, "A1 + B1"},
+		{ParseOptions{}, " =  SUM( A1 ,\n\tB1 )  "},
+		{ParseOptions{}, "=IF(A1>0,\r\n  \"yes\",\r\n  \"no\"\r\n)\r\n"},
+		{ParseOptions{}, "=- 2 + - -3"},
+		{ParseOptions{}, "= ( A1:C5  B2:D8 )"},
+		{ParseOptions{}, "=SUM( ( A1 , C1 ) )"},
+		{ParseOptions{}, "={ 1 , 2 ; 3 , 4 }"},
+		{ParseOptions{}, "=LET( x , 1 ,\n  y , x * 2 ,\n  x + y )"},
+		{ParseOptions{}, "=LAMBDA( a , b , a + b )( 1 , 2 )"},
+		{ParseOptions{}, "=IF( A1 , , 0 )"},
+		{ParseOptions{}, "=SUM( Sheet1:Sheet3!A1 , 'My Sheet'!B2 , [1]Sheet1!C3 )"},
+		{ParseOptions{}, "=Table1[ [#Headers], [Col A] ] & A2#"},
+		{ParseOptions{}, "= 5 % * @A1:B2"},
+		{ParseOptions{}, "=A1 &\n\"x\"&\n'y'!A1"},
+		{ParseOptions{R1C1: true}, "=SUM( R1C1 : R[-1]C )"},
+		{ParseOptions{Locale: LocaleDE}, "=SUMME( A1 ; 1,5 ;{1 . 2})"},
+	}
+	for _, test := range tests {
+		node, err := NewParserWithOptions(test.src, test.options).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		if got := Source(node); got != test.src {
+			t.Errorf("For input '%q', expected '%q', got '%q'", test.src, test.src, got)
+		}
+	}
+}
+
+func TestSourceTrivia(t *testing.T) {
+	node, err := NewParser(" = A1 +\nB1 \n").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var binary = node.(BinaryExpr)
+	var first = binary.Left.(CellExpr).Ident
+	if len(first.Leading) != 3 || first.Leading[0].Raw != " " || first.Leading[1].Type != Equal || first.Leading[2].Raw != " " {
+		t.Errorf("Expected the leading trivia ' ', '=', ' ', got %v", first.Leading)
+	}
+	var last = binary.Right.(CellExpr).Ident
+	if len(last.Leading) != 1 || last.Leading[0].Raw != "\n" || last.Leading[0].Type != Whitespace {
+		t.Errorf("Expected the leading trivia '\\n', got %v", last.Leading)
+	}
+	if len(last.Trailing) != 1 || last.Trailing[0].Raw != " \n" {
+		t.Errorf("Expected the trailing trivia ' \\n', got %v", last.Trailing)
+	}
+
+	// replacing B1 keeps the surrounding formatting
+	binary.Right = CellExpr{Ident: &Token{Type: Cell, Raw: "C2", Leading: last.Leading}}
+	if got := Source(binary); got != " = A1 +\nC2" {
+		t.Errorf("Expected ' = A1 +\\nC2', got '%q'", got)
+	}
+
+	// constructed nodes without tokens use the default syntax
+	var call = FunCallExpr{
+		Name:      &Token{Type: Ident, Raw: "SUM"},
+		Arguments: []Node{binary.Left, EmptyArgExpr{}},
+	}
+	if got := Source(call); got != "SUM( = A1,)" {
+		t.Errorf("Expected 'SUM( = A1,)', got '%q'", got)
+	}
+}
@@ -0,0 +1,601 @@
+package excelformulaparser
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxTextLength is the maximum number of characters of a text value.
+const maxTextLength = 32767
+
+// The text functions count characters as Unicode code points, and positions
+// (e.g., of MID and FIND) start at 1.
+
+func init() {
+	register("LEN", 1, 1, scalar(func(args []Value) Value {
+		var c converter
+		var s = c.text(args[0])
+		if c.err != 0 {
+			return NewError(c.err)
+		}
+		return NewNumber(float64(utf8.RuneCountInString(s)))
+	}))
+	register("LEFT", 1, 2, scalar(func(args []Value) Value {
+		var c converter
+		var s, n = []rune(c.text(args[0])), c.number(optionalArg(args, 1, NewNumber(1)))
+		switch {
+		case c.err != 0:
+			return NewError(c.err)
+		case n < 0:
+			return NewError(ErrorValue)
+		}
+		return NewString(string(s[:int(min(n, float64(len(s))))]))
+	}))
+	register("RIGHT", 1, 2, scalar(func(args []Value) Value {
+		var c converter
+		var s, n = []rune(c.text(args[0])), c.number(optionalArg(args, 1, NewNumber(1)))
+		switch {
+		case c.err != 0:
+			return NewError(c.err)
+		case n < 0:
+			return NewError(ErrorValue)
+		}
+		return NewString(string(s[len(s)-int(min(n, float64(len(s)))):]))
+	}))
+	register("MID", 3, 3, scalar(func(args []Value) Value {
+		var c converter
+		var s, start, n = []rune(c.text(args[0])), c.number(args[1]), c.number(args[2])
+		switch {
+		case c.err != 0:
+			return NewError(c.err)
+		case start < 1 || n < 0:
+			return NewError(ErrorValue)
+		}
+		var from = int(min(start-1, float64(len(s))))
+		return NewString(string(s[from:int(min(float64(from)+n, float64(len(s))))]))
+	}))
+	register("FIND", 2, 3, scalar(func(args []Value) Value {
+		return find(args, func(pattern string) *regexp.Regexp {
+			return regexp.MustCompile(regexp.QuoteMeta(pattern))
+		})
+	}))
+	register("SEARCH", 2, 3, scalar(func(args []Value) Value {
+		return find(args, wildcardPattern)
+	}))
+	register("SUBSTITUTE", 3, 4, scalar(substitute))
+	register("REPLACE", 4, 4, scalar(func(args []Value) Value {
+		var c converter
+		var s, start, n, replacement = []rune(c.text(args[0])), c.number(args[1]), c.number(args[2]), c.text(args[3])
+		switch {
+		case c.err != 0:
+			return NewError(c.err)
+		case start < 1 || n < 0:
+			return NewError(ErrorValue)
+		}
+		var from = int(min(start-1, float64(len(s))))
+		var to = int(min(float64(from)+n, float64(len(s))))
+		return NewString(string(s[:from]) + replacement + string(s[to:]))
+	}))
+	register("UPPER", 1, 1, scalar(textFunc(strings.ToUpper)))
+	register("LOWER", 1, 1, scalar(textFunc(strings.ToLower)))
+	register("PROPER", 1, 1, scalar(textFunc(proper)))
+	register("TRIM", 1, 1, scalar(textFunc(func(s string) string {
+		return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == ' ' }), " ")
+	})))
+	register("CLEAN", 1, 1, scalar(textFunc(func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r < 32 {
+				return -1
+			}
+			return r
+		}, s)
+	})))
+	register("REPT", 2, 2, scalar(func(args []Value) Value {
+		var c converter
+		var s, n = c.text(args[0]), math.Trunc(c.number(args[1]))
+		switch {
+		case c.err != 0:
+			return NewError(c.err)
+		case n < 0 || n > maxTextLength || n*float64(utf8.RuneCountInString(s)) > maxTextLength:
+			return NewError(ErrorValue)
+		}
+		return NewString(strings.Repeat(s, int(n)))
+	}))
+	register("EXACT", 2, 2, scalar(func(args []Value) Value {
+		var c converter
+		var a, b = c.text(args[0]), c.text(args[1])
+		if c.err != 0 {
+			return NewError(c.err)
+		}
+		return NewBool(a == b)
+	}))
+	register("VALUE", 1, 1, scalar(func(args []Value) Value {
+		if args[0].Kind == ValueBool {
+			return NewError(ErrorValue)
+		}
+		x, kind := toNumber(args[0])
+		if kind != 0 {
+			return NewError(kind)
+		}
+		return NewNumber(x)
+	}))
+
+	register("CONCATENATE", 1, 255, scalar(func(args []Value) Value {
+		var c converter
+		var sb strings.Builder
+		for _, arg := range args {
+			sb.WriteString(c.text(arg))
+		}
+		return joined(sb.String(), c.err)
+	}))
+	register("CONCAT", 1, 253, func(c *funcCall) (Value, error) {
+		texts, kind, err := c.texts(0, false)
+		if err != nil {
+			return Value{}, err
+		}
+		return joined(strings.Join(texts, ""), kind), nil
+	})
+	register("TEXTJOIN", 3, 252, textJoin)
+	register("TEXTBEFORE", 2, 6, textBeforeAfter(true))
+	register("TEXTAFTER", 2, 6, textBeforeAfter(false))
+	register("TEXTSPLIT", 2, 6, textSplit)
+
+	register("CHAR", 1, 1, numeric(func(x []float64) Value {
+		var n = math.Trunc(x[0])
+		if n < 1 || n > 255 {
+			return NewError(ErrorValue)
+		}
+		return NewString(string(windows1252(byte(n))))
+	}))
+	register("CODE", 1, 1, scalar(func(args []Value) Value {
+		var c converter
+		var s = c.text(args[0])
+		switch {
+		case c.err != 0:
+			return NewError(c.err)
+		case s == "":
+			return NewError(ErrorValue)
+		}
+		var r, _ = utf8.DecodeRuneInString(s)
+		for i := 1; i <= 255; i++ {
+			if windows1252(byte(i)) == r {
+				return NewNumber(float64(i))
+			}
+		}
+		return NewNumber('?') // not in the character set
+	}))
+	register("UNICHAR", 1, 1, numeric(func(x []float64) Value {
+		var n = math.Trunc(x[0])
+		if n < 1 || n > unicode.MaxRune || n >= 0xD800 && n <= 0xDFFF {
+			return NewError(ErrorValue)
+		}
+		return NewString(string(rune(n)))
+	}))
+	register("UNICODE", 1, 1, scalar(func(args []Value) Value {
+		var c converter
+		var s = c.text(args[0])
+		switch {
+		case c.err != 0:
+			return NewError(c.err)
+		case s == "":
+			return NewError(ErrorValue)
+		}
+		var r, _ = utf8.DecodeRuneInString(s)
+		return NewNumber(float64(r))
+	}))
+	register("TEXT", 2, 2, scalar(func(args []Value) Value {
+		var c converter
+		var format = c.text(args[1])
+		if c.err != 0 {
+			return NewError(c.err)
+		}
+		return formatValue(args[0], format)
+	}))
+}
+
+// optionalArg returns args[i], or def if the argument is omitted.
+func optionalArg(args []Value, i int, def Value) Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return def
+}
+
+// defaultArg returns args[i], or def if the argument is omitted or empty,
+// like the newer functions (e.g., TEXTBEFORE) do.
+func defaultArg(args []Value, i int, def Value) Value {
+	if i < len(args) && args[i].Kind != ValueBlank {
+		return args[i]
+	}
+	return def
+}
+
+// textFunc returns a function of a single text.
+func textFunc(f func(s string) string) func(args []Value) Value {
+	return func(args []Value) Value {
+		var c converter
+		var s = c.text(args[0])
+		if c.err != 0 {
+			return NewError(c.err)
+		}
+		return NewString(f(s))
+	}
+}
+
+// joined returns a joined text, or #VALUE! if it is too long.
+func joined(s string, err ErrorKind) Value {
+	switch {
+	case err != 0:
+		return NewError(err)
+	case utf8.RuneCountInString(s) > maxTextLength:
+		return NewError(ErrorValue)
+	}
+	return NewString(s)
+}
+
+// texts returns the values of the arguments from the i-th on as texts, in
+// the order of CONCAT and TEXTJOIN, or the first error among them. Empty
+// texts are skipped if ignoreEmpty is true.
+func (c *funcCall) texts(i int, ignoreEmpty bool) ([]string, ErrorKind, error) {
+	var texts []string
+	for ; i < len(c.args); i++ {
+		values, _, err := c.flatten(i)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, v := range values {
+			var conv converter
+			var s = conv.text(v)
+			if conv.err != 0 {
+				return nil, conv.err, nil
+			}
+			if s != "" || !ignoreEmpty {
+				texts = append(texts, s)
+			}
+		}
+	}
+	return texts, 0, nil
+}
+
+// find implements FIND(find_text, within_text, [start_num]) and SEARCH, the
+// position of the first match of the pattern compiled by compile.
+func find(args []Value, compile func(pattern string) *regexp.Regexp) Value {
+	var c converter
+	var pattern, s, start = c.text(args[0]), []rune(c.text(args[1])), c.number(optionalArg(args, 2, NewNumber(1)))
+	switch {
+	case c.err != 0:
+		return NewError(c.err)
+	case start < 1 || start > float64(len(s)+1):
+		return NewError(ErrorValue)
+	}
+	var from = int(start) - 1
+	var match = compile(pattern).FindStringIndex(string(s[from:]))
+	if match == nil {
+		return NewError(ErrorValue)
+	}
+	return NewNumber(float64(from + utf8.RuneCountInString(string(s[from:])[:match[0]]) + 1))
+}
+
+// wildcardPattern returns a case-insensitive regular expression for a pattern
+// with the wildcards of SEARCH: ? matches a character, * any characters, and
+// ~ escapes the next character.
+func wildcardPattern(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("(?is)")
+	var runes = []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch runes[i] {
+		case '?':
+			sb.WriteString(".")
+		case '*':
+			sb.WriteString(".*?")
+		case '~':
+			if i+1 < len(runes) {
+				i++ // the escaped character
+			}
+			fallthrough
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		}
+	}
+	return regexp.MustCompile(sb.String())
+}
+
+// substitute implements SUBSTITUTE(text, old_text, new_text, [instance_num]).
+func substitute(args []Value) Value {
+	var c converter
+	var s, old, replacement = c.text(args[0]), c.text(args[1]), c.text(args[2])
+	var instance = 0.0 // all
+	if len(args) > 3 {
+		instance = math.Trunc(c.number(args[3]))
+		if c.err == 0 && instance < 1 {
+			return NewError(ErrorValue)
+		}
+	}
+	switch {
+	case c.err != 0:
+		return NewError(c.err)
+	case old == "":
+		return NewString(s)
+	case instance == 0:
+		return NewString(strings.ReplaceAll(s, old, replacement))
+	}
+	var offset = 0
+	for n := 1.0; ; n++ {
+		var i = strings.Index(s[offset:], old)
+		if i < 0 {
+			return NewString(s)
+		}
+		if n == instance {
+			return NewString(s[:offset+i] + replacement + s[offset+i+len(old):])
+		}
+		offset += i + len(old)
+	}
+}
+
+// proper capitalizes the first letter of each word, and lowers the other
+// letters. Like Excel, any character other than a letter separates words.
+func proper(s string) string {
+	var runes = []rune(s)
+	for i, r := range runes {
+		if i == 0 || !unicode.IsLetter(runes[i-1]) {
+			runes[i] = unicode.ToUpper(r)
+		} else {
+			runes[i] = unicode.ToLower(r)
+		}
+	}
+	return string(runes)
+}
+
+// textJoin implements TEXTJOIN(delimiter, ignore_empty, text1, ...). The
+// delimiter may be an array of delimiters, which are used in turn.
+func textJoin(c *funcCall) (Value, error) {
+	delimiters, _, err := c.flatten(0)
+	if err != nil {
+		return Value{}, err
+	}
+	ignoreEmpty, err := c.value(1)
+	if err != nil {
+		return Value{}, err
+	}
+	var conv converter
+	var separators = make([]string, len(delimiters))
+	for i, delimiter := range delimiters {
+		separators[i] = conv.text(delimiter)
+	}
+	if len(separators) == 0 {
+		separators = []string{""} // e.g., the empty array {}
+	}
+	var ignore = conv.bool(ignoreEmpty)
+	if conv.err != 0 {
+		return NewError(conv.err), nil
+	}
+	texts, kind, err := c.texts(2, ignore)
+	if err != nil {
+		return Value{}, err
+	}
+	if kind != 0 {
+		return NewError(kind), nil
+	}
+	var sb strings.Builder
+	for i, s := range texts {
+		if i > 0 {
+			sb.WriteString(separators[(i-1)%len(separators)])
+		}
+		sb.WriteString(s)
+	}
+	return joined(sb.String(), 0), nil
+}
+
+// delimiters returns the texts of a delimiter argument, which may be an array
+// of delimiters. An omitted argument has no delimiters.
+func (c *funcCall) delimiters(i int) ([]string, ErrorKind, error) {
+	if i >= len(c.args) {
+		return nil, 0, nil
+	}
+	values, _, err := c.flatten(i)
+	if err != nil {
+		return nil, 0, err
+	}
+	var conv converter
+	var delimiters []string
+	for _, v := range values {
+		if v.Kind != ValueBlank { // an omitted delimiter, unlike ""
+			delimiters = append(delimiters, conv.text(v))
+		}
+	}
+	return delimiters, conv.err, nil
+}
+
+// A textMatch is the position of a delimiter in a text, in characters.
+type textMatch struct {
+	start, end int
+}
+
+// splitMatches returns the positions of the delimiters in s from left to
+// right, the longest one if several delimiters match at a position.
+func splitMatches(s []rune, delimiters []string, ignoreCase bool) []textMatch {
+	var matches []textMatch
+	for i := 0; i < len(s); {
+		var length = -1
+		for _, delimiter := range delimiters {
+			var d = []rune(delimiter)
+			if len(d) > length && len(d) <= len(s)-i && equalText(string(s[i:i+len(d)]), delimiter, ignoreCase) {
+				length = len(d)
+			}
+		}
+		if length <= 0 {
+			i++
+			continue
+		}
+		matches = append(matches, textMatch{i, i + length})
+		i += length
+	}
+	return matches
+}
+
+func equalText(a, b string, ignoreCase bool) bool {
+	if ignoreCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// textBeforeAfter returns TEXTBEFORE(text, delimiter, [instance_num],
+// [match_mode], [match_end], [if_not_found]) or TEXTAFTER. A negative
+// instance_num counts the delimiters from the end of the text.
+func textBeforeAfter(before bool) builtin {
+	return func(c *funcCall) (Value, error) {
+		delimiters, kind, err := c.delimiters(1)
+		if err != nil {
+			return Value{}, err
+		}
+		var args = make([]Value, 0, len(c.args))
+		for i := range c.args {
+			if i == 1 {
+				continue // the delimiters are not applied element-wise
+			}
+			v, err := c.value(i)
+			if err != nil {
+				return Value{}, err
+			}
+			args = append(args, v)
+		}
+		return lift(args, func(args []Value) Value {
+			var conv converter
+			var s = []rune(conv.text(args[0]))
+			if conv.err == 0 {
+				conv.err = kind // of the delimiters
+			}
+			var instance = math.Trunc(conv.number(defaultArg(args, 1, NewNumber(1))))
+			var ignoreCase = conv.number(defaultArg(args, 2, NewNumber(0))) != 0
+			var matchEnd = conv.number(defaultArg(args, 3, NewNumber(0))) != 0
+			switch {
+			case conv.err != 0:
+				return NewError(conv.err)
+			case instance == 0 || math.Abs(instance) > float64(max(len(s), 1)):
+				return NewError(ErrorValue)
+			}
+			var matches []textMatch
+			if slices.Contains(delimiters, "") { // matches at the start and the end
+				matches = []textMatch{{0, 0}, {len(s), len(s)}}
+			} else {
+				matches = splitMatches(s, delimiters, ignoreCase)
+			}
+			var n = int(instance) - 1
+			if instance < 0 {
+				if matchEnd {
+					matches = append([]textMatch{{0, 0}}, matches...)
+				}
+				n = len(matches) + int(instance)
+			} else if matchEnd {
+				matches = append(matches, textMatch{len(s), len(s)})
+			}
+			if n < 0 || n >= len(matches) {
+				return defaultArg(args, 4, NewError(ErrorNA))
+			}
+			if before {
+				return NewString(string(s[:matches[n].start]))
+			}
+			return NewString(string(s[matches[n].end:]))
+		}), nil
+	}
+}
+
+// textSplit implements TEXTSPLIT(text, col_delimiter, [row_delimiter],
+// [ignore_empty], [match_mode], [pad_with]), which splits a text into an
+// array of rows and columns.
+func textSplit(c *funcCall) (Value, error) {
+	var args = make([]Value, len(c.args))
+	for i := range c.args {
+		if i == 1 || i == 2 {
+			continue // the delimiters
+		}
+		v, err := c.value(i)
+		if err != nil {
+			return Value{}, err
+		}
+		args[i] = v
+	}
+	colDelimiters, kind, err := c.delimiters(1)
+	if err != nil {
+		return Value{}, err
+	}
+	rowDelimiters, rowKind, err := c.delimiters(2)
+	if err != nil {
+		return Value{}, err
+	}
+	if kind == 0 {
+		kind = rowKind
+	}
+	if args[0].Kind == ValueArray {
+		return NewError(ErrorValue), nil
+	}
+	var conv converter
+	var s = []rune(conv.text(args[0]))
+	var ignoreEmpty = conv.bool(defaultArg(args, 3, NewBool(false)))
+	var ignoreCase = conv.number(defaultArg(args, 4, NewNumber(0))) != 0
+	var padWith = defaultArg(args, 5, NewError(ErrorNA))
+	switch {
+	case conv.err != 0:
+		return NewError(conv.err), nil
+	case len(colDelimiters) == 0 && len(rowDelimiters) == 0, slices.Contains(colDelimiters, ""), slices.Contains(rowDelimiters, ""):
+		return NewError(ErrorValue), nil
+	}
+	var split = func(s []rune, delimiters []string) [][]rune {
+		var parts [][]rune
+		var from = 0
+		for _, m := range splitMatches(s, delimiters, ignoreCase) {
+			parts = append(parts, s[from:m.start])
+			from = m.end
+		}
+		parts = append(parts, s[from:])
+		if ignoreEmpty {
+			parts = slices.DeleteFunc(parts, func(part []rune) bool { return len(part) == 0 })
+		}
+		return parts
+	}
+	var rows [][]Value
+	var cols = 0
+	for _, line := range split(s, rowDelimiters) {
+		var row []Value
+		for _, part := range split(line, colDelimiters) {
+			row = append(row, NewString(string(part)))
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+			cols = max(cols, len(row))
+		}
+	}
+	if len(rows) == 0 {
+		return NewError(ErrorCalc), nil // an empty array
+	}
+	for i, row := range rows {
+		for len(row) < cols {
+			row = append(row, padWith)
+		}
+		rows[i] = row
+	}
+	return NewArray(rows), nil
+}
+
+// windows1252 returns the character of a code in the Windows-1252 character
+// set of CHAR and CODE. Codes that Windows-1252 leaves undefined map to the
+// Unicode code point of the same number.
+func windows1252(code byte) rune {
+	if code >= 0x80 && code <= 0x9F {
+		if r := windows1252High[code-0x80]; r != 0 {
+			return r
+		}
+	}
+	return rune(code)
+}
+
+var windows1252High = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
@@ -0,0 +1,246 @@
+package excelformulaparser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextFunctions(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`=LEN("héllo")`, "5"},
+		{`=LEN("a""b")`, "3"},
+		{"=LEN(1/4)", "4"},
+		{"=LEN(C1)", "0"},
+		{`=LEN({"a","bc"})`, "{1,2}"},
+		{"=LEN(#N/A)", "#N/A"},
+		{`=LEFT("日本語")`, `"日"`},
+		{`=LEFT("abc", 2)`, `"ab"`},
+		{`=LEFT("abc", 10)`, `"abc"`},
+		{`=LEFT("abc", -1)`, "#VALUE!"},
+		{`=RIGHT("abc", 2)`, `"bc"`},
+		{`=RIGHT("abc", 0)`, `""`},
+		{`=MID("abcdef", 2, 3)`, `"bcd"`},
+		{`=MID("abcdef", 5, 10)`, `"ef"`},
+		{`=MID("abcdef", 10, 1)`, `""`},
+		{`=MID("abcdef", 0, 1)`, "#VALUE!"},
+		{`=FIND("b", "abcb")`, "2"},
+		{`=FIND("b", "abcb", 3)`, "4"},
+		{`=FIND("B", "abc")`, "#VALUE!"},
+		{`=FIND("", "abc", 2)`, "2"},
+		{`=FIND("a", "abc", 5)`, "#VALUE!"},
+		{`=FIND("ö", "schön")`, "4"},
+		{`=SEARCH("B", "abc")`, "2"},
+		{`=SEARCH("b?d", "abcd")`, "2"},
+		{`=SEARCH("a*d", "xabcd")`, "2"},
+		{`=SEARCH("~*", "a*b")`, "2"},
+		{`=SEARCH("~?", "ab")`, "#VALUE!"},
+		{`=SEARCH(".", "a.b")`, "2"},
+		{`=SUBSTITUTE("a-b-c", "-", "+")`, `"a+b+c"`},
+		{`=SUBSTITUTE("a-b-c", "-", "+", 2)`, `"a-b+c"`},
+		{`=SUBSTITUTE("a-b-c", "-", "+", 3)`, `"a-b-c"`},
+		{`=SUBSTITUTE("a-b-c", "-", "+", 0)`, "#VALUE!"},
+		{`=SUBSTITUTE("abc", "", "x")`, `"abc"`},
+		{`=REPLACE("abcdef", 2, 3, "X")`, `"aXef"`},
+		{`=REPLACE("abc", 5, 1, "X")`, `"abcX"`},
+		{`=UPPER("straße")`, `"STRAßE"`},
+		{`=LOWER("ÀB")`, `"àb"`},
+		{`=PROPER("this is a TITLE")`, `"This Is A Title"`},
+		{`=PROPER("2-way street's")`, `"2-Way Street'S"`},
+		{`=TRIM("  a   b  ")`, `"a b"`},
+		{`=CLEAN("a"&CHAR(9)&"b")`, `"ab"`},
+		{`=CONCAT("a", 1, TRUE)`, `"a1TRUE"`},
+		{"=CONCAT(A1:B2)", `"1102x"`},
+		{`=CONCAT({"a","b";"c","d"})`, `"abcd"`},
+		{"=CONCAT(A1, #REF!)", "#REF!"},
+		{`=CONCATENATE("a", "b", 1)`, `"ab1"`},
+		{`=CONCATENATE({"a","b"}, "!")`, `{"a!","b!"}`},
+		{`=TEXTJOIN(", ", TRUE, "a", "", C1, "b")`, `"a, b"`},
+		{`=TEXTJOIN(", ", FALSE, "a", "", "b")`, `"a, , b"`},
+		{`=TEXTJOIN({"-","+"}, TRUE, 1, 2, 3, 4)`, `"1-2+3-4"`},
+		{`=TEXTJOIN("", TRUE, A1:A3)`, `"123"`},
+		{`=TEXTJOIN({}, TRUE, "a", "b")`, `"ab"`},
+		{`=TEXTJOIN(",", "x", 1)`, "#VALUE!"},
+		{`=TEXTBEFORE("a.b.c", ".")`, `"a"`},
+		{`=TEXTBEFORE("a.b.c", ".", 2)`, `"a.b"`},
+		{`=TEXTBEFORE("a.b.c", ".", -1)`, `"a.b"`},
+		{`=TEXTBEFORE("a.b.c", "-")`, "#N/A"},
+		{`=TEXTBEFORE("a.b.c", "-", , , , "none")`, `"none"`},
+		{`=TEXTBEFORE("a.b.c", "-", 1, 0, 1)`, `"a.b.c"`},
+		{`=TEXTBEFORE("aXb", "x")`, "#N/A"},
+		{`=TEXTBEFORE("aXb", "x", 1, 1)`, `"a"`},
+		{`=TEXTBEFORE("a.b,c", {",","."})`, `"a"`},
+		{`=TEXTBEFORE("abc", "")`, `""`},
+		{`=TEXTBEFORE("abc", "b", 0)`, "#VALUE!"},
+		{`=TEXTBEFORE({"a.b","c.d"}, ".")`, `{"a","c"}`},
+		{`=TEXTAFTER("a.b.c", ".")`, `"b.c"`},
+		{`=TEXTAFTER("a.b.c", ".", -1)`, `"c"`},
+		{`=TEXTAFTER("a.b.c", ".", 3)`, "#N/A"},
+		{`=TEXTAFTER("a.b.c", ".", -3, , 1)`, `"a.b.c"`},
+		{`=TEXTAFTER("abc", "")`, `"abc"`},
+		{`=TEXTSPLIT("a,b,c", ",")`, `{"a","b","c"}`},
+		{`=TEXTSPLIT("a,b;c", ",", ";")`, `{"a","b";"c",#N/A}`},
+		{`=TEXTSPLIT("a,b;c", ",", ";", , , "-")`, `{"a","b";"c","-"}`},
+		{`=TEXTSPLIT("a;b", , ";")`, `{"a";"b"}`},
+		{`=TEXTSPLIT("a,,b", ",")`, `{"a","","b"}`},
+		{`=TEXTSPLIT("a,,b", ",", , TRUE)`, `{"a","b"}`},
+		{`=TEXTSPLIT("a1b2c", {"1","2"})`, `{"a","b","c"}`},
+		{`=TEXTSPLIT("aXbxc", "x", , , 1)`, `{"a","b","c"}`},
+		{`=TEXTSPLIT("abc", "")`, "#VALUE!"},
+		{`=REPT("ab", 3)`, `"ababab"`},
+		{`=REPT("ab", 0)`, `""`},
+		{`=REPT("ab", 20000)`, "#VALUE!"},
+		{`=REPT("", 1E20)`, "#VALUE!"},
+		{`=EXACT("a", "A")`, "FALSE"},
+		{`=EXACT("1", 1)`, "TRUE"},
+		{`=VALUE("1,234.5")`, "1234.5"},
+		{`=VALUE("10%")`, "0.1"},
+		{`=VALUE("x")`, "#VALUE!"},
+		{"=VALUE(TRUE)", "#VALUE!"},
+		{"=CHAR(65)", `"A"`},
+		{"=CHAR(128)", `"€"`},
+		{"=CHAR(0)", "#VALUE!"},
+		{`=CODE("A")`, "65"},
+		{`=CODE("€")`, "128"},
+		{`=CODE("")`, "#VALUE!"},
+		{"=UNICHAR(8364)", `"€"`},
+		{"=UNICHAR(55296)", "#VALUE!"},
+		{`=UNICODE("😀")`, "128512"},
+		{`=UNICODE("")`, "#VALUE!"},
+		{`=LEFT("abc", "x")`, "#VALUE!"},
+		{`=MID(#N/A, #DIV/0!, 1)`, "#N/A"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		v, err := Evaluate(node, &EvalContext{Resolver: testCells})
+		if err != nil {
+			t.Errorf("For input '%s', unexpected error: %v", test.src, err)
+			continue
+		}
+		if got := v.String(); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+	}
+
+	node, err := NewParser(`=CONCAT(REPT("x", 20000), REPT("y", 20000))`).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if v, err := Evaluate(node, nil); err != nil || v.String() != "#VALUE!" {
+		t.Errorf("Expected #VALUE! for a text longer than %d characters, got %v, %v", maxTextLength, v, err)
+	}
+	node, err = NewParser(`=LEN(REPT("é", 32767))`).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if v, err := Evaluate(node, nil); err != nil || v.String() != "32767" {
+		t.Errorf("Expected 32767, got %v, %v", v, err)
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	tests := []struct {
+		value    Value
+		format   string
+		expected string
+	}{
+		{NewNumber(1234.567), "0", "1235"},
+		{NewNumber(1234.567), "0.00", "1234.57"},
+		{NewNumber(1234.567), "#,##0.00", "1,234.57"},
+		{NewNumber(1234567), "#,##0", "1,234,567"},
+		{NewNumber(1234567), "0,", "1235"},
+		{NewNumber(1234567), "0.0,,", "1.2"},
+		{NewNumber(0.5), "0%", "50%"},
+		{NewNumber(0.1234), "0.0%", "12.3%"},
+		{NewNumber(0.5), "#.##", ".5"},
+		{NewNumber(2), "0.##", "2."},
+		{NewNumber(2.5), "0.0?", "2.5 "},
+		{NewNumber(5), "000", "005"},
+		{NewNumber(5), "#", "5"},
+		{NewNumber(0), "#", ""},
+		{NewNumber(2.675), "0.00", "2.68"},
+		{NewNumber(-1.5), "0.0", "-1.5"},
+		{NewNumber(-1.5), "0.0;(0.0)", "(1.5)"},
+		{NewNumber(0), "0;-0;\"zero\"", "zero"},
+		{NewNumber(1234.5), "$#,##0.00", "$1,234.50"},
+		{NewNumber(5551234), "000-0000", "555-1234"},
+		{NewNumber(12345), "0.00E+00", "1.23E+04"},
+		{NewNumber(0.00012), "0.0E+0", "1.2E-4"},
+		{NewNumber(9.999), "0.00E+00", "1.00E+01"},
+		{NewNumber(12345), "##0.0E+0", "12.3E+3"},
+		{NewNumber(12), `0 "items"`, "12 items"},
+		{NewNumber(12), `\#0`, "#12"},
+		{NewNumber(1.5), "General", "1.5"},
+		{NewNumber(-1.5), "General", "-1.5"},
+		{NewNumber(1), "@", "1"},
+		{NewNumber(1.5), "x@", "x1.5"},
+		{NewNumber(-2), "@", "-2"},
+		{NewNumber(7), "[Red]0.0", "7.0"},
+		{NewNumber(7), "[Color10]0", "7"},
+		{NewNumber(7), "[$€-407]0.00", "€7.00"},
+		{NewNumber(-7), "[<0]0;0", "#VALUE!"},
+		{NewNumber(7), "[DBNum1]0", "#VALUE!"},
+		{NewNumber(1e308), "#,##0.00", "100" + strings.Repeat(",000", 102) + ".00"},
+		{NewNumber(1e20), "#,##0", "100,000,000,000,000,000,000"},
+		{NewNumber(1.23456789012345678e20), "0", "123456789012346000000"},
+		{NewNumber(1e308), "0%", "#NUM!"},
+		{NewNumber(3.5), "# ?/?", "3 1/2"},
+		{NewNumber(-3.5), "# ?/?", "-3 1/2"},
+		{NewNumber(3.14159), "# ?/?", "3 1/7"},
+		{NewNumber(0.75), "?/?", "3/4"},
+		{NewNumber(3.5), "?/?", "7/2"},
+		{NewNumber(2.25), "# ?/8", "2 2/8"},
+		{NewNumber(0.3), "?/10", "3/10"},
+		{NewNumber(1.5), "# ??/??", "1  1/2 "},
+		{NewNumber(3), "# ?/?", "3    "},
+		{NewNumber(2.99), "# ?/?", "3    "},
+		{NewNumber(45322), "yyyy-mm-dd", "2024-01-31"},
+		{NewNumber(45322), "m/d/yy", "1/31/24"},
+		{NewNumber(45322), "dddd, mmmm d, yyyy", "Wednesday, January 31, 2024"},
+		{NewNumber(45322), "ddd mmm", "Wed Jan"},
+		{NewNumber(45322), "mmmmm", "J"},
+		{NewNumber(45322.75), "h:mm AM/PM", "6:00 PM"},
+		{NewNumber(45322.75), "hh:mm:ss", "18:00:00"},
+		{NewNumber(0.5), "h:mm a/p", "12:00 p"},
+		{NewNumber(0.000694444), "h:mm", "0:01"},
+		{NewNumber(60), "yyyy-mm-dd", "1900-02-29"},
+		{NewNumber(61), "yyyy-mm-dd", "1900-03-01"},
+		{NewNumber(1), "dddd", "Sunday"},
+		{NewNumber(1), "[h]:mm", "24:00"},
+		{NewNumber(1.5), "[h]:mm:ss", "36:00:00"},
+		{NewNumber(0.5), "[mm]:ss", "720:00"},
+		{NewNumber(0.0001), "[ss]", "09"},
+		{NewNumber(-1), "yyyy", "#VALUE!"},
+		{NewString("12.5"), "0.00", "12.50"},
+		{NewString("abc"), "0.00", "abc"},
+		{NewString("abc"), "@!", "abc!"},
+		{NewString("abc"), `0;-0;0;"text: "@`, "text: abc"},
+		{NewBool(true), "0", "TRUE"},
+		{Value{}, "0.0", "0.0"},
+		{NewError(ErrorNA), "0", "#N/A"},
+	}
+	for _, test := range tests {
+		var got = formatValue(test.value, test.format)
+		var s = got.Text
+		if got.Kind != ValueString {
+			s = got.String()
+		}
+		if s != test.expected {
+			t.Errorf("For TEXT(%s, %q), expected '%s', got '%s'", test.value, test.format, test.expected, s)
+		}
+	}
+
+	node, err := NewParser(`=TEXT(A1:A2, "0.0")`).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if v, err := Evaluate(node, &EvalContext{Resolver: testCells}); err != nil || v.String() != `{"1.0";"2.0"}` {
+		t.Errorf(`Expected {"1.0";"2.0"}, got %v, %v`, v, err)
+	}
+}
@@ -0,0 +1,200 @@
+package excelformulaparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ValueKind is the type of a Value.
+type ValueKind int
+
+const (
+	ValueBlank     ValueKind = iota // An empty cell or omitted argument
+	ValueNumber                     // A number (e.g., 1.5), dates are serial numbers
+	ValueString                     // A text (e.g., "abc")
+	ValueBool                       // TRUE or FALSE
+	ValueError                      // An error value (e.g., #DIV/0!)
+	ValueArray                      // An array of values (e.g., {1,2;3,4})
+	ValueReference                  // A reference to cells (e.g., A1:B2)
+	ValueLambda                     // A function created by LAMBDA
+)
+
+func (k ValueKind) String() string {
+	switch k {
+	case ValueBlank:
+		return "Blank"
+	case ValueNumber:
+		return "Number"
+	case ValueString:
+		return "String"
+	case ValueBool:
+		return "Bool"
+	case ValueError:
+		return "Error"
+	case ValueArray:
+		return "Array"
+	case ValueReference:
+		return "Reference"
+	case ValueLambda:
+		return "Lambda"
+	default:
+		return "ValueKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Value is the result of an evaluation. The zero Value is blank.
+type Value struct {
+	Kind   ValueKind
+	Number float64   // The number of a ValueNumber
+	Text   string    // The text of a ValueString
+	Bool   bool      // The value of a ValueBool
+	Error  ErrorKind // The error of a ValueError
+	Array  [][]Value // The [row][column] elements of a ValueArray
+	Areas  []Area    // The areas of a ValueReference, more than one for a union (e.g., (A1,C1))
+	lambda *closure  // The function of a ValueLambda
+}
+
+func NewNumber(number float64) Value {
+	return Value{Kind: ValueNumber, Number: number}
+}
+
+func NewString(text string) Value {
+	return Value{Kind: ValueString, Text: text}
+}
+
+func NewBool(b bool) Value {
+	return Value{Kind: ValueBool, Bool: b}
+}
+
+func NewError(kind ErrorKind) Value {
+	return Value{Kind: ValueError, Error: kind}
+}
+
+// NewArray returns an array of the [row][column] elements.
+func NewArray(elements [][]Value) Value {
+	return Value{Kind: ValueArray, Array: elements}
+}
+
+// NewReference returns a reference to the areas.
+func NewReference(areas ...Area) Value {
+	return Value{Kind: ValueReference, Areas: areas}
+}
+
+// String returns the value as written in a formula, e.g. 1.5, "abc", TRUE,
+// #N/A, {1,2;3,4} or Sheet1!A1:B2. A blank value is written as the empty string.
+func (v Value) String() string {
+	switch v.Kind {
+	case ValueBlank:
+		return ""
+	case ValueNumber:
+		return formatNumber(v.Number)
+	case ValueString:
+		return `"` + strings.ReplaceAll(v.Text, `"`, `""`) + `"`
+	case ValueBool:
+		if v.Bool {
+			return "TRUE"
+		}
+		return "FALSE"
+	case ValueError:
+		return v.Error.String()
+	case ValueArray:
+		var sb strings.Builder
+		sb.WriteString("{")
+		for i, row := range v.Array {
+			if i > 0 {
+				sb.WriteString(";")
+			}
+			for j, element := range row {
+				if j > 0 {
+					sb.WriteString(",")
+				}
+				sb.WriteString(element.String())
+			}
+		}
+		sb.WriteString("}")
+		return sb.String()
+	case ValueReference:
+		var sb strings.Builder
+		for i, area := range v.Areas {
+			if i > 0 {
+				sb.WriteString(",")
+			}
+			sb.WriteString(area.String())
+		}
+		return sb.String()
+	case ValueLambda:
+		return "LAMBDA"
+	default:
+		return v.Kind.String()
+	}
+}
+
+// The size of a worksheet.
+const (
+	MaxRows    = 1048576
+	MaxColumns = 16384
+)
+
+// An Area is a rectangular range of cells.
+type Area struct {
+	Sheet            string // The sheet name, EvalContext.Sheet for references without a sheet name
+	Row, Col         int    // The zero-based first row and column
+	LastRow, LastCol int    // The zero-based last row and column
+}
+
+// Rows returns the number of rows of the area.
+func (a Area) Rows() int {
+	return a.LastRow - a.Row + 1
+}
+
+// Cols returns the number of columns of the area.
+func (a Area) Cols() int {
+	return a.LastCol - a.Col + 1
+}
+
+// String returns the area in A1 notation, e.g. Sheet1!A1:B2, A:A or 1:1.
+func (a Area) String() string {
+	var sb strings.Builder
+	if a.Sheet != "" {
+		sb.WriteString(quoteSheetName(a.Sheet))
+		sb.WriteString("!")
+	}
+	switch {
+	case a.Row == 0 && a.LastRow == MaxRows-1: // full columns
+		sb.WriteString(colIndexToName(a.Col))
+		sb.WriteString(":")
+		sb.WriteString(colIndexToName(a.LastCol))
+	case a.Col == 0 && a.LastCol == MaxColumns-1: // full rows
+		sb.WriteString(strconv.Itoa(a.Row + 1))
+		sb.WriteString(":")
+		sb.WriteString(strconv.Itoa(a.LastRow + 1))
+	default:
+		sb.WriteString(colIndexToName(a.Col))
+		sb.WriteString(strconv.Itoa(a.Row + 1))
+		if a.Rows() > 1 || a.Cols() > 1 {
+			sb.WriteString(":")
+			sb.WriteString(colIndexToName(a.LastCol))
+			sb.WriteString(strconv.Itoa(a.LastRow + 1))
+		}
+	}
+	return sb.String()
+}
+
+// colIndexToName returns the name of the zero-based column, e.g. AA for 26.
+func colIndexToName(col int) string {
+	var name []byte
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = append([]byte{byte('A' + (col-1)%26)}, name...)
+	}
+	return string(name)
+}
+
+// quoteSheetName quotes a sheet name unless it can be written as is (e.g., Sheet1).
+func quoteSheetName(name string) string {
+	for i, ch := range name {
+		if !isASCIILetter(ch) && ch != '_' && ch != '.' && (i == 0 || !isDigit(ch)) {
+			return "'" + strings.ReplaceAll(name, "'", "''") + "'"
+		}
+	}
+	return name
+}
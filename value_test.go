@@ -0,0 +1,35 @@
+package excelformulaparser
+
+import "testing"
+
+func TestValueString(t *testing.T) {
+	tests := []struct {
+		value    Value
+		expected string
+	}{
+		{Value{}, ""},
+		{NewNumber(1.5), "1.5"},
+		{NewNumber(-2), "-2"},
+		{NewString(`say "hi"`), `"say ""hi"""`},
+		{NewBool(false), "FALSE"},
+		{NewError(ErrorNA), "#N/A"},
+		{NewArray([][]Value{{NewNumber(1), NewString("a")}, {NewBool(true), {}}}), `{1,"a";TRUE,}`},
+		{NewReference(Area{Row: 0, Col: 0, LastRow: 0, LastCol: 0}), "A1"},
+		{NewReference(Area{Sheet: "My Sheet", Row: 1, Col: 26, LastRow: 9, LastCol: 27}, Area{Row: 4, Col: 2, LastRow: 4, LastCol: 2}), "'My Sheet'!AA2:AB10,C5"},
+		{NewReference(Area{Sheet: "Sheet1", Row: 0, Col: 1, LastRow: MaxRows - 1, LastCol: 1}), "Sheet1!B:B"},
+		{NewReference(Area{Row: 2, Col: 0, LastRow: 3, LastCol: MaxColumns - 1}), "3:4"},
+	}
+	for _, test := range tests {
+		if got := test.value.String(); got != test.expected {
+			t.Errorf("For %s value, expected '%s', got '%s'", test.value.Kind, test.expected, got)
+		}
+	}
+}
+
+func Test_colIndexToName(t *testing.T) {
+	for _, name := range []string{"A", "Z", "AA", "AZ", "BA", "ZZ", "AAA", "XFD"} {
+		if got := colIndexToName(colNameToIndex(name)); got != name {
+			t.Errorf("colIndexToName(colNameToIndex(%s)) = %s", name, got)
+		}
+	}
+}
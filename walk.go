@@ -0,0 +1,103 @@
+package excelformulaparser
+
+import "fmt"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order: It starts by calling
+// v.Visit(node); node must not be nil. If the visitor w returned by
+// v.Visit(node) is not nil, Walk is invoked recursively with visitor
+// w for each of the non-nil children of node, followed by a call of
+// w.Visit(nil).
+//
+// Children are visited in source order, e.g. the binding values of a LetExpr
+// before its body. Tokens (e.g., the names bound by LET) are not nodes.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case LiteralExpr, CellExpr, R1C1Expr, IdentExpr, StructuredRefExpr, EmptyArgExpr:
+		// nothing to do
+
+	case RangeExpr:
+		Walk(v, n.Begin)
+		walkList(v, n.Ends)
+
+	case SheetRefExpr:
+		Walk(v, n.Ref)
+
+	case Sheet3DRefExpr:
+		Walk(v, n.Ref)
+
+	case ExternalRefExpr:
+		Walk(v, n.Ref)
+
+	case BinaryExpr:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+
+	case UnaryExpr:
+		Walk(v, n.Operand)
+
+	case ParenthesizedExpr:
+		Walk(v, n.Inner)
+
+	case FunCallExpr:
+		walkList(v, n.Arguments)
+
+	case CallExpr:
+		Walk(v, n.Callee)
+		walkList(v, n.Arguments)
+
+	case LetExpr:
+		for _, binding := range n.Bindings {
+			Walk(v, binding.Value)
+		}
+		Walk(v, n.Body)
+
+	case LambdaExpr:
+		Walk(v, n.Body)
+
+	case ArrayExpr:
+		for _, row := range n.Elements {
+			walkList(v, row)
+		}
+
+	default:
+		panic(fmt.Sprintf("excelformulaparser.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkList(v Visitor, list []Node) {
+	for _, node := range list {
+		if node != nil {
+			Walk(v, node)
+		}
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order: It starts by calling
+// f(node); node must not be nil. If f returns true, Inspect invokes f
+// recursively for each of the non-nil children of node, followed by a
+// call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
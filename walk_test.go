@@ -0,0 +1,109 @@
+package excelformulaparser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"=A1+B1*2", "BinaryExpr CellExpr BinaryExpr CellExpr LiteralExpr"},
+		{"=SUM(A1:B2, (C1), -D1%)", "FunCallExpr RangeExpr CellExpr CellExpr ParenthesizedExpr CellExpr UnaryExpr UnaryExpr CellExpr"},
+		{"={1,2;3,4}", "ArrayExpr LiteralExpr LiteralExpr LiteralExpr LiteralExpr"},
+		{"=IF(A1,,Sheet1!B2#)", "FunCallExpr CellExpr EmptyArgExpr UnaryExpr SheetRefExpr CellExpr"},
+		{"=SUM(Jan:Dec!B2, [1]Sheet1!A1, Table1[Col])", "FunCallExpr Sheet3DRefExpr CellExpr ExternalRefExpr CellExpr StructuredRefExpr"},
+		{"=LET(x, A1*2, x+1)", "LetExpr BinaryExpr CellExpr LiteralExpr BinaryExpr IdentExpr LiteralExpr"},
+		{"=LAMBDA(a, b, a+b)(1, 2)", "CallExpr LambdaExpr BinaryExpr IdentExpr IdentExpr LiteralExpr LiteralExpr"},
+		{"=(A1,B1) C1", "BinaryExpr ParenthesizedExpr BinaryExpr CellExpr CellExpr CellExpr"},
+	}
+	for _, test := range tests {
+		node, err := NewParser(test.src).Parse()
+		if err != nil {
+			t.Errorf("Parse error for '%s': %v", test.src, err)
+			continue
+		}
+		var types []string
+		var depth = 0
+		Inspect(node, func(n Node) bool {
+			if n == nil {
+				depth--
+				return false
+			}
+			depth++
+			types = append(types, strings.TrimPrefix(fmt.Sprintf("%T", n), "excelformulaparser."))
+			return true
+		})
+		if got := strings.Join(types, " "); got != test.expected {
+			t.Errorf("For input '%s', expected '%s', got '%s'", test.src, test.expected, got)
+		}
+		if depth != 0 {
+			t.Errorf("For input '%s', expected a nil visit after each node, got depth %d", test.src, depth)
+		}
+	}
+
+	var r1c1, err = NewParserWithOptions("=R1C1+RC[1]", ParseOptions{R1C1: true}).Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var count = 0
+	Inspect(r1c1, func(n Node) bool {
+		if _, ok := n.(R1C1Expr); ok {
+			count++
+		}
+		return true
+	})
+	if count != 2 {
+		t.Errorf("Expected 2 R1C1Expr nodes, got %d", count)
+	}
+}
+
+func TestInspectPrune(t *testing.T) {
+	node, err := NewParser("=SUM(A1, B1) + IF(C1, D1, E1)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var cells []string
+	Inspect(node, func(n Node) bool {
+		if call, ok := n.(FunCallExpr); ok && call.Name.Raw == "IF" {
+			return false // skip the arguments of IF
+		}
+		if cell, ok := n.(CellExpr); ok {
+			cells = append(cells, cell.Ident.Raw)
+		}
+		return true
+	})
+	if got := strings.Join(cells, ","); got != "A1,B1" {
+		t.Errorf("Expected 'A1,B1', got '%s'", got)
+	}
+}
+
+type countingVisitor map[string]int
+
+func (c countingVisitor) Visit(node Node) Visitor {
+	if node != nil {
+		c[strings.TrimPrefix(fmt.Sprintf("%T", node), "excelformulaparser.")]++
+	}
+	return c
+}
+
+func TestWalk(t *testing.T) {
+	node, err := NewParser("=SUM(A1:A3, {1,2}) & LET(x, 1, x)").Parse()
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	var counts = countingVisitor{}
+	Walk(counts, node)
+	var expected = map[string]int{"BinaryExpr": 1, "FunCallExpr": 1, "RangeExpr": 1, "CellExpr": 2, "ArrayExpr": 1, "LiteralExpr": 3, "LetExpr": 1, "IdentExpr": 1}
+	for name, count := range expected {
+		if counts[name] != count {
+			t.Errorf("Expected %d %s nodes, got %d", count, name, counts[name])
+		}
+	}
+	if len(counts) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+}